@@ -1,29 +1,76 @@
 package main
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"net/http"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
+	"dizzycode1112/github-discord-bridge/internal/backpressure"
+	"dizzycode1112/github-discord-bridge/internal/coalesce"
 	"dizzycode1112/github-discord-bridge/internal/config"
+	"dizzycode1112/github-discord-bridge/internal/delivery"
+	"dizzycode1112/github-discord-bridge/internal/digest"
 	"dizzycode1112/github-discord-bridge/internal/discord"
 	"dizzycode1112/github-discord-bridge/internal/github"
+	"dizzycode1112/github-discord-bridge/internal/health"
+	"dizzycode1112/github-discord-bridge/internal/middleware"
+	"dizzycode1112/github-discord-bridge/internal/notifier"
+	"dizzycode1112/github-discord-bridge/internal/reconcile"
 	"dizzycode1112/github-discord-bridge/internal/storage"
+	"dizzycode1112/github-discord-bridge/internal/teams"
 	"dizzycode1112/github-discord-bridge/pkg/applogger"
 
 	"github.com/gin-gonic/gin"
+	rabbitmq "weedza.shop/rabbitmq"
 )
 
+// maxListedFiles PR Opened 訊息中最多列出的變更檔案數
+const maxListedFiles = 10
+
+// 建立 thread 的 lock：持有上限（涵蓋 GitHub + Discord API 呼叫）與等待時的輪詢間隔
+const (
+	threadLockTTL          = 30 * time.Second
+	threadLockPollInterval = 200 * time.Millisecond
+)
+
+// appStore main 需要的 storage 功能：PR mapping、CI 合併、digest 與關閉連線
+// RedisStore 與 MemoryStore（DRY_RUN）都實作
+type appStore interface {
+	storage.Store
+	storage.CIStore
+	storage.DigestStore
+	Close() error
+}
+
 type App struct {
-	store         storage.Store
-	discordClient *discord.Client
-	githubSecret  string
+	ctx             context.Context // 背景處理（async consumer）用，shutdown 逾時後取消
+	store           storage.Store
+	notifier        notifier.Notifier
+	githubClient    *github.Client // 未設定 GITHUB_TOKEN 時為 nil
+	announceChannel string
+	publisher       *delivery.Publisher // ASYNC_DELIVERY 開啟時不為 nil
+	digestStore     storage.DigestStore
+	digest          *digest.Scheduler // 設定 DIGEST_REPOS 時不為 nil
+	adminToken      string
+	health          *health.Checker
+	ciCoalescer     *coalesce.Coalescer // 設定 CI_COALESCE_WINDOW 時不為 nil
+	// 同時處理 webhook 的上限，設定 DISCORD_MAX_CONCURRENCY 時不為 nil
+	limiter *backpressure.Limiter
 }
 
+// storeRetryAfter store 無法使用時回 503 帶的 Retry-After（秒）
+const storeRetryAfter = "30"
+
+// healthCheckTimeout 單次 /health probe 所有檢查的時間上限
+const healthCheckTimeout = 3 * time.Second
+
 func main() {
 	config.Load()
 	cfg := config.AppConfig
@@ -33,71 +80,263 @@ func main() {
 	log := applogger.Log
 	defer log.Flush()
 
-	// 初始化 storage
-	store, err := storage.NewRedisStore(cfg.RedisURL)
-	if err != nil {
-		log.Error("Failed to connect to Redis", "error", err)
-		panic(err)
+	// 套用 THEME_COLORS 的配色，之後所有 formatter 都從這個 theme 取色
+	discord.SetTheme(cfg.Theme)
+
+	if cfg.GitHubWebhookSecret == "" && cfg.Env == "production" {
+		log.Warn("GITHUB_WEBHOOK_SECRET is not set, webhook signature verification is disabled")
+	}
+
+	// 初始化 storage：DRY_RUN 產生的是假的 thread ID，只存在記憶體，不寫進正式的 Redis
+	var store appStore
+	if cfg.DryRun {
+		store = storage.NewMemoryStore()
+		log.Warn("DRY_RUN enabled, PR mappings are kept in memory only and not written to Redis")
+	} else {
+		redisStore, err := storage.NewRedisStore(cfg.RedisURL, cfg.RedisKeyPrefix)
+		if err != nil {
+			log.Error("Failed to connect to Redis", "error", err)
+			panic(err)
+		}
+		store = redisStore
 	}
 	defer store.Close()
 
-	// 初始化 Discord client
-	discordClient := discord.NewClient(cfg.DiscordBotToken, cfg.DiscordForumChID)
+	// 收到 SIGINT/SIGTERM 時開始 graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 背景處理與 in-flight webhook 的上層 context，shutdown 逾時後取消以中止仍在進行的對外請求
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
+	// 初始化通知後端
+	var n notifier.Notifier
+	switch cfg.NotifierBackend {
+	case "teams":
+		teamsClient := teams.NewClient(cfg.TeamsServiceURL, cfg.TeamsChannelID, cfg.TeamsAppID, cfg.TeamsAppPassword)
+		if cfg.DryRun {
+			teamsClient.EnableDryRun()
+			log.Warn("DRY_RUN enabled, Teams requests will only be logged")
+		}
+		n = teamsClient
+	case "discord_webhook":
+		webhookClient := discord.NewWebhookClient(cfg.DiscordWebhookURL, &discord.ClientOptions{
+			Timeout:   cfg.DiscordTimeout,
+			RateLimit: cfg.DiscordRateLimit,
+		})
+		if cfg.DryRun {
+			webhookClient.EnableDryRun()
+			log.Warn("DRY_RUN enabled, Discord requests will only be logged")
+		}
+		n = webhookClient
+	default:
+		discordClient := discord.NewClient(cfg.DiscordBotToken, cfg.DiscordForumChID, &discord.ClientOptions{
+			Timeout:             cfg.DiscordTimeout,
+			MaxIdleConnsPerHost: cfg.DiscordMaxIdleConnsPerHost,
+			RateLimit:           cfg.DiscordRateLimit,
+			AutoArchiveDuration: cfg.DiscordAutoArchiveDuration,
+		})
+		if cfg.DryRun {
+			discordClient.EnableDryRun()
+			log.Warn("DRY_RUN enabled, Discord requests will only be logged")
+		}
+
+		// 啟動時先驗證 token 與 forum channel，設定錯誤直接 fail fast
+		if cfg.SkipDiscordVerify {
+			log.Warn("SKIP_DISCORD_VERIFY enabled, skipping Discord credentials check")
+		} else if err := discordClient.Verify(ctx); err != nil {
+			log.Error("Discord verification failed, check DISCORD_BOT_TOKEN and DISCORD_FORUM_CHANNEL_ID", "error", err)
+			panic(err)
+		}
+		n = discordClient
+	}
+	log.Info("Notifier backend initialized", "backend", cfg.NotifierBackend)
+
+	// 初始化 GitHub API client（可選）
+	var githubClient *github.Client
+	if cfg.GitHubToken != "" {
+		githubClient = github.NewClient(cfg.GitHubToken, cfg.GitHubAPIURL)
+	}
 
 	app := &App{
-		store:         store,
-		discordClient: discordClient,
-		githubSecret:  cfg.GitHubWebhookSecret,
+		ctx:             requestCtx,
+		store:           store,
+		notifier:        n,
+		githubClient:    githubClient,
+		announceChannel: cfg.DiscordAnnounceChID,
 	}
 
-	// 設定 Gin router
-	r := gin.Default()
+	// 啟動時 reconcile（背景執行，不阻擋 server 啟動）
+	if cfg.ReconcileOnStartup {
+		if githubClient == nil {
+			log.Warn("RECONCILE_ON_STARTUP requires GITHUB_TOKEN, skipping reconciliation")
+		} else {
+			go func() {
+				summary := reconcile.Run(ctx, store, n, githubClient, cfg.ReconcileInterval)
+				log.Info("Startup reconciliation finished", "checked", summary.Checked, "archived", summary.Archived, "failed", summary.Failed)
+			}()
+		}
+	}
 
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// async delivery：webhook 只負責 publish，consumer 負責呼叫 Discord（失敗會依 backoff 重試）
+	var mqConn *rabbitmq.Connection
+	if cfg.AsyncDelivery {
+		mqConn = rabbitmq.NewConnection(rabbitmq.Config{URL: cfg.RabbitMQURL, Prefetch: 10}, log)
+		if err := mqConn.Connect(); err != nil {
+			log.Error("Failed to connect to RabbitMQ", "error", err)
+			panic(err)
+		}
 
-	r.POST("/webhook/github", app.handleGitHubWebhook)
+		// consumer 負責宣告 queue（含 DLQ 參數），必須在 publisher 開始送之前啟動
+		if err := delivery.StartConsumer(mqConn, cfg.DeliveryQueue, app.handleQueuedEvent); err != nil {
+			log.Error("Failed to start delivery consumer", "error", err)
+			panic(err)
+		}
+		app.publisher = delivery.NewPublisher(mqConn, cfg.DeliveryQueue)
+		log.Info("Async delivery enabled", "queue", cfg.DeliveryQueue)
+	}
+
+	// digest 模式：定期彙整發送，ctx 取消（收到 shutdown signal）時 scheduler 結束
+	digestDone := make(chan struct{})
+	if len(cfg.DigestRepos) > 0 {
+		app.digestStore = store
+		app.digest = digest.NewScheduler(store, n, cfg.DigestInterval)
+		go func() {
+			defer close(digestDone)
+			app.digest.Run(ctx)
+		}()
+		log.Info("Digest mode enabled", "repos", cfg.DigestRepos, "interval", cfg.DigestInterval.String())
+	} else {
+		close(digestDone)
+	}
 
-	log.Info("Server starting", "port", cfg.Port)
-	if err := r.Run(":" + cfg.Port); err != nil {
-		log.Error("Failed to start server", "error", err)
+	// 同一個 commit 的 CI 結果合併成一則通知
+	if cfg.CICoalesceWindow > 0 {
+		app.ciCoalescer = coalesce.New(store, cfg.CICoalesceWindow, app.postCISummary)
+		log.Info("CI coalescing enabled", "window", cfg.CICoalesceWindow.String())
+	}
+
+	// 限制同時處理 webhook 的數量（backpressure），與 Discord client 的 rate limit 分開
+	if cfg.DiscordMaxConcurrency > 0 {
+		app.limiter = backpressure.New(cfg.DiscordMaxConcurrency, cfg.DiscordConcurrencyWait, cfg.DiscordConcurrencyBacklog)
+		log.Info("Webhook concurrency limit enabled", "max", cfg.DiscordMaxConcurrency, "wait", cfg.DiscordConcurrencyWait.String(), "backlog", cfg.DiscordConcurrencyBacklog)
+	}
+
+	// 設定 Gin router（用結構化 request log 與 JSON panic recovery 取代 gin 預設的 Logger、Recovery）
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Error("Invalid TRUSTED_PROXIES", "error", err)
 		panic(err)
 	}
-}
+	// Recovery 放在 RequestLogger 之後，panic 的請求才會以 500 記錄
+	r.Use(middleware.RequestID(), middleware.RequestLogger(log), middleware.Recovery(log))
 
-func (app *App) handleGitHubWebhook(c *gin.Context) {
-	log := applogger.Log
+	// readiness：Redis 每次 ping（DRY_RUN 不連 Redis）；Discord 選擇性檢查並快取結果；async delivery 時包含 RabbitMQ 連線
+	app.health = health.NewChecker()
+	if !cfg.DryRun {
+		app.health.Add("redis", store.Ping)
+	}
+	if verifier, ok := n.(notifier.Verifier); ok && cfg.HealthCheckDiscord {
+		app.health.Add("discord", health.Cached(cfg.HealthDiscordInterval, verifier.Verify))
+	}
+	if mqConn != nil {
+		app.health.Add("rabbitmq", func(context.Context) error {
+			if !mqConn.IsConnected() {
+				return errors.New("not connected")
+			}
+			return nil
+		})
+	}
+	r.GET("/health", app.handleHealth)
 
-	body, err := io.ReadAll(c.Request.Body)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "failed to read body"})
-		return
+	// allowlist 與 rate limit 在讀取 body、驗證 signature 之前先擋下
+	var webhookHandlers []gin.HandlerFunc
+	if len(cfg.WebhookAllowedIPs) > 0 {
+		webhookHandlers = append(webhookHandlers, middleware.IPAllowlist(cfg.WebhookAllowedIPs, log))
+	}
+	if cfg.WebhookRateLimit > 0 {
+		webhookHandlers = append(webhookHandlers, middleware.RateLimit(float64(cfg.WebhookRateLimit), cfg.WebhookRateBurst, log))
+	}
+	webhookHandlers = append(webhookHandlers, middleware.GitHubWebhook(cfg.GitHubWebhookSecret, cfg.SignatureSchemes, cfg.Replay, cfg.WebhookMaxBodyBytes, log), app.handleGitHubWebhook)
+	r.POST("/webhook/github", webhookHandlers...)
+
+	// Admin API（需設定 ADMIN_TOKEN）
+	if cfg.AdminToken != "" {
+		app.adminToken = cfg.AdminToken
+		admin := r.Group("/admin", app.requireAdminToken)
+		admin.POST("/digest/flush", app.handleDigestFlush)
+
+		if cfg.DebugEndpoints {
+			debug := r.Group("/debug", app.requireAdminToken)
+			debug.GET("/threads", app.handleDebugThreads)
+		}
 	}
 
-	// 驗證 webhook signature
-	if app.githubSecret != "" {
-		signature := c.GetHeader("X-Hub-Signature-256")
-		if signature == "" {
-			c.JSON(401, gin.H{"error": "missing signature"})
-			return
+	srv := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: r,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Info("Server starting", "port", cfg.Port)
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Failed to start server", "error", err)
+			panic(err)
 		}
+	case <-ctx.Done():
+	}
 
-		if !verifySignature(body, signature, app.githubSecret) {
-			c.JSON(401, gin.H{"error": "invalid signature"})
-			return
+	log.Info("Shutting down server", "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// 先等 in-flight webhook 處理完，逾時就取消仍在進行的對外請求
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("Server shutdown did not complete in time", "error", err)
+	}
+	// enqueue 模式排隊中的 webhook 也要處理完
+	if app.limiter != nil {
+		app.limiter.Close(shutdownCtx)
+	}
+	// 不再有新的 publish 後，讓 consumer 把已收到的事件處理完再關閉連線
+	if mqConn != nil {
+		if err := mqConn.Drain(shutdownCtx); err != nil {
+			log.Error("RabbitMQ drain did not complete in time", "error", err)
 		}
 	}
+	<-digestDone
+	// 還在時間窗內的 CI 結果直接發送，不等到時間窗結束
+	if app.ciCoalescer != nil {
+		app.ciCoalescer.Close(shutdownCtx)
+	}
+	cancelRequests()
+
+	log.Info("Server stopped")
+}
+
+func (app *App) handleGitHubWebhook(c *gin.Context) {
+	log := applogger.Log
+
+	// header、body 大小與 signature 已由 middleware.GitHubWebhook 驗證
+	webhook := middleware.GetWebhook(c)
+	body := webhook.Body
 
 	// 處理 ping event（GitHub 建立 webhook 時發送）
-	ghEvent := c.GetHeader("X-GitHub-Event")
+	ghEvent := webhook.Event
 	if ghEvent == "ping" {
 		log.Info("Received GitHub ping")
 		c.JSON(200, gin.H{"status": "pong"})
 		return
 	}
 
-	// 解析 webhook payload（body 已被 ReadAll 消耗，用 json.Unmarshal）
+	// 解析 webhook payload（body 已被 middleware 讀取，用 json.Unmarshal）
 	var payload github.WebhookPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
 		log.Error("Failed to parse webhook payload", "error", err)
@@ -105,24 +344,60 @@ func (app *App) handleGitHubWebhook(c *gin.Context) {
 		return
 	}
 
-	log.Info("Received GitHub event", "ghEvent", ghEvent, "action", payload.Action)
+	log.Info("Received GitHub event", "ghEvent", ghEvent, "action", payload.Action, "deliveryID", webhook.DeliveryID, "requestID", middleware.GetRequestID(c))
+
+	// async 模式：只負責把事件丟進 queue，實際的 Discord 呼叫由 consumer 處理
+	if app.publisher != nil {
+		event := delivery.Event{
+			Version:     delivery.SchemaVersion,
+			DeliveryID:  webhook.DeliveryID,
+			GitHubEvent: ghEvent,
+			Payload:     body,
+			ReceivedAt:  time.Now(),
+		}
+		if err := app.publisher.Publish(event); err != nil {
+			log.Error("Failed to enqueue event", "ghEvent", ghEvent, "error", err)
+			c.JSON(500, gin.H{"error": "failed to enqueue event"})
+			return
+		}
+		c.JSON(200, gin.H{"status": "queued"})
+		return
+	}
 
-	// check_suite 獨立處理（payload 不一定有 pull_request，不走 handleEvent）
-	// handleCheckSuiteCompleted 內部對個別 PR 的失敗用 continue 跳過，
-	// 這裡的 err 只處理整體性錯誤（例如 check_suite 欄位缺失），回 500 讓 GitHub retry。
-	if ghEvent == "workflow_run" {
-		if payload.Action == "completed" {
-			if err := app.handleWorkflowRunCompleted(&payload); err != nil {
-				log.Error("Failed to handle workflow_run", "error", err)
-				c.JSON(500, gin.H{"error": "failed to process event"})
+	// 同時處理的數量已達上限：排隊在背景處理（enqueue）或請 GitHub 稍後重送（shed）
+	if app.limiter != nil {
+		release, ok := app.limiter.Acquire(c.Request.Context())
+		if !ok {
+			queued := app.limiter.Enqueue(func() {
+				if err := app.dispatch(app.ctx, ghEvent, &payload); err != nil {
+					log.Error("Failed to handle queued event", "ghEvent", ghEvent, "action", payload.Action, "deliveryID", webhook.DeliveryID, "error", err)
+				}
+			})
+			if queued {
+				c.JSON(200, gin.H{"status": "queued"})
 				return
 			}
+			log.Warn("Too many events in flight, shedding", "ghEvent", ghEvent, "action", payload.Action, "deliveryID", webhook.DeliveryID)
+			c.JSON(503, gin.H{"error": "too many events in flight"})
+			return
 		}
-		c.JSON(200, gin.H{"status": "processed"})
-		return
+		defer release()
 	}
 
-	if err := app.handleEvent(ghEvent, &payload); err != nil {
+	// 同步模式：store 與通知後端的呼叫跟著 request context（client 斷線時中止），shutdown 逾時時也一併取消
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	stopOnShutdown := context.AfterFunc(app.ctx, cancel)
+	defer stopOnShutdown()
+
+	if err := app.dispatch(ctx, ghEvent, &payload); err != nil {
+		// Redis 暫時連不上：回 503 表示可以稍後重送，與處理本身失敗（500）區分
+		if errors.Is(err, storage.ErrStoreUnavailable) {
+			log.Error("Store unavailable, event not processed", "ghEvent", ghEvent, "action", payload.Action, "deliveryID", webhook.DeliveryID, "error", err)
+			c.Header("Retry-After", storeRetryAfter)
+			c.JSON(503, gin.H{"error": "store unavailable"})
+			return
+		}
 		log.Error("Failed to handle event", "ghEvent", ghEvent, "action", payload.Action, "error", err)
 		c.JSON(500, gin.H{"error": "failed to process event"})
 		return
@@ -131,7 +406,49 @@ func (app *App) handleGitHubWebhook(c *gin.Context) {
 	c.JSON(200, gin.H{"status": "processed"})
 }
 
-func (app *App) handleEvent(ghEvent string, payload *github.WebhookPayload) error {
+// dispatch 依事件類型分派到對應的 handler（同步處理與 async consumer 共用）
+func (app *App) dispatch(ctx context.Context, ghEvent string, payload *github.WebhookPayload) error {
+	// bridge 自己發的留言（POST_BACK_TO_GITHUB）不處理，避免回應自己造成迴圈
+	if payload.Comment != nil && strings.Contains(payload.Comment.Body, github.BridgeCommentMarker) {
+		applogger.Log.Info("Ignoring comment posted by the bridge", "ghEvent", ghEvent, "commentID", payload.Comment.ID)
+		return nil
+	}
+
+	switch ghEvent {
+	case "workflow_run":
+		if !config.AppConfig.EventEnabled(config.EventCI) {
+			return nil
+		}
+		// workflow_run 獨立處理（payload 不一定有 pull_request，不走 handleEvent）
+		// handleWorkflowRunCompleted 內部對個別 PR 的失敗用 continue 跳過，
+		// 這裡的 err 只代表整體性錯誤（例如 workflow_run 欄位缺失），讓 GitHub / consumer retry。
+		if payload.Action != "completed" {
+			return nil
+		}
+		return app.handleWorkflowRunCompleted(ctx, payload)
+	case "release":
+		// release 不屬於任何 PR，直接發到公告 channel
+		if payload.Action != "published" || !config.AppConfig.EventEnabled(config.EventRelease) {
+			return nil
+		}
+		return app.handleReleasePublished(ctx, payload)
+	default:
+		return app.handleEvent(ctx, ghEvent, payload)
+	}
+}
+
+// handleQueuedEvent async consumer 收到事件後的處理入口
+func (app *App) handleQueuedEvent(event delivery.Event) error {
+	var payload github.WebhookPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return rabbitmq.NewPoisonError(fmt.Errorf("failed to parse queued payload: %w", err))
+	}
+
+	applogger.Log.Info("Processing queued GitHub event", "ghEvent", event.GitHubEvent, "action", payload.Action, "deliveryID", event.DeliveryID)
+	return app.dispatch(app.ctx, event.GitHubEvent, &payload)
+}
+
+func (app *App) handleEvent(ctx context.Context, ghEvent string, payload *github.WebhookPayload) error {
 	log := applogger.Log
 
 	pr := payload.PullRequest
@@ -147,23 +464,46 @@ func (app *App) handleEvent(ghEvent string, payload *github.WebhookPayload) erro
 
 	repoFullName := payload.Repository.FullName
 
+	// mergeable_state 的變化獨立於 updated 通知判斷（EVENTS_ENABLED 的 conflict）
+	if ghEvent == "pull_request" && (payload.Action == "synchronize" || payload.Action == "edited") {
+		app.checkMergeableState(ctx, prID, pr, repoFullName)
+	}
+
+	// EVENTS_ENABLED 關閉的通知在做任何事之前就略過
+	event := notificationType(ghEvent, payload)
+	if event != "" && !config.AppConfig.EventEnabled(event) {
+		log.Info("Event disabled by EVENTS_ENABLED, ignoring", "ghEvent", ghEvent, "action", payload.Action, "event", event)
+		return nil
+	}
+
+	// digest 模式的 repo 不即時通知，只累積 opened/merged/closed
+	if app.digest != nil && config.AppConfig.DigestEnabled(repoFullName) {
+		return app.recordDigest(ctx, event, pr, repoFullName)
+	}
+
 	switch ghEvent {
 	case "pull_request":
 		switch payload.Action {
 		case "opened":
-			return app.handlePROpened(prID, pr, repoFullName)
+			return app.handlePROpened(ctx, prID, pr, repoFullName)
 		case "synchronize":
-			return app.handlePRUpdated(prID, pr, repoFullName)
+			return app.handlePRUpdated(ctx, prID, pr, repoFullName)
 		case "closed":
 			if pr.Merged {
-				return app.handlePRMerged(prID, pr, payload.Sender.Login, repoFullName)
+				return app.handlePRMerged(ctx, prID, pr, payload.Sender.Login, repoFullName)
 			}
-			return app.handlePRClosed(prID, pr, payload.Sender.Login, repoFullName)
+			return app.handlePRClosed(ctx, prID, pr, payload.Sender.Login, repoFullName)
 		case "reopened":
-			return app.handlePRReopened(prID, pr, repoFullName)
+			return app.handlePRReopened(ctx, prID, pr, repoFullName)
 		case "review_requested":
-			return app.handleReviewRequested(prID, pr, payload.RequestedReviewer, payload.Sender.Login, repoFullName)
-		case "review_request_removed", "edited", "labeled", "unlabeled", "assigned", "unassigned":
+			return app.handleReviewRequested(ctx, prID, pr, payload.RequestedReviewer, payload.Sender.Login, repoFullName)
+		case "edited":
+			return app.handlePREdited(ctx, prID, pr, payload.Changes, repoFullName)
+		case "assigned", "unassigned":
+			return app.handleAssignment(ctx, prID, pr, payload.Assignee, payload.Sender.Login, payload.Action == "assigned", repoFullName)
+		case "ready_for_review", "converted_to_draft":
+			return app.handleDraftChanged(ctx, prID, pr)
+		case "review_request_removed", "labeled", "unlabeled":
 			return nil
 		default:
 			log.Warn("Unhandled pull_request action", "action", payload.Action)
@@ -174,8 +514,14 @@ func (app *App) handleEvent(ghEvent string, payload *github.WebhookPayload) erro
 			log.Info("Ignoring pull_request_review action", "action", payload.Action)
 			return nil
 		}
-		return app.handlePRReviewed(prID, pr, payload.Review, repoFullName)
-	case "issue_comment", "pull_request_review_comment":
+		return app.handlePRReviewed(ctx, prID, pr, payload.Review, repoFullName)
+	case "pull_request_review_comment":
+		if payload.Action != "created" {
+			log.Info("Ignoring pull_request_review_comment action", "action", payload.Action)
+			return nil
+		}
+		return app.handleReviewComment(ctx, prID, pr, payload.Comment, repoFullName)
+	case "issue_comment":
 		log.Info("Ignoring comment event", "ghEvent", ghEvent)
 		return nil
 	default:
@@ -184,16 +530,213 @@ func (app *App) handleEvent(ghEvent string, payload *github.WebhookPayload) erro
 	}
 }
 
-func (app *App) handlePROpened(prID string, pr *github.PullRequest, repoFullName string) error {
+// recordDigest 把 digest 模式 repo 的 PR 事件存起來，等 scheduler 彙整發送
+func (app *App) recordDigest(ctx context.Context, event string, pr *github.PullRequest, repoFullName string) error {
+	switch event {
+	case config.EventOpened, config.EventMerged, config.EventClosed:
+	default:
+		return nil
+	}
+
+	return app.digestStore.AppendDigest(ctx, storage.DigestEntry{
+		Repo:   repoFullName,
+		Kind:   event,
+		Number: pr.Number,
+		Title:  pr.Title,
+		URL:    pr.HTMLURL,
+		Author: pr.User.Login,
+		At:     time.Now(),
+	})
+}
+
+// handleHealth 回報各依賴的狀態，任一失敗時回 503 讓 orchestrator 停止導入流量
+func (app *App) handleHealth(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	healthy, checks := app.health.Run(ctx)
+	if !healthy {
+		c.JSON(503, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+	c.JSON(200, gin.H{"status": "ok", "checks": checks})
+}
+
+// requireAdminToken 檢查 X-Admin-Token header
+func (app *App) requireAdminToken(c *gin.Context) {
+	token := c.GetHeader("X-Admin-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(app.adminToken)) != 1 {
+		c.AbortWithStatusJSON(401, gin.H{"error": "invalid admin token"})
+		return
+	}
+	c.Next()
+}
+
+// handleDigestFlush 手動觸發 digest 發送
+func (app *App) handleDigestFlush(c *gin.Context) {
+	if app.digest == nil {
+		c.JSON(404, gin.H{"error": "digest mode is not enabled"})
+		return
+	}
+
+	sent, err := app.digest.Flush(c.Request.Context())
+	if err != nil {
+		applogger.Log.Error("Manual digest flush failed", "error", err)
+		c.JSON(500, gin.H{"error": "failed to flush digest", "repos": sent})
+		return
+	}
+	c.JSON(200, gin.H{"status": "flushed", "repos": sent})
+}
+
+// threadMapping /debug/threads 回傳的單一 PR → thread 對應
+type threadMapping struct {
+	PRID       string `json:"prId"`
+	ThreadID   string `json:"threadId"`
+	Closed     bool   `json:"closed"`               // MarkAsClosed 過（有 TTL）
+	TTLSeconds int64  `json:"ttlSeconds,omitempty"` // 剩餘秒數，未關閉時省略
+}
+
+// handleDebugThreads 查看 store 內容：帶 ?pr=owner/repo%23123 時回傳單一 PR，否則列出所有未關閉的對應
+func (app *App) handleDebugThreads(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if prID := c.Query("pr"); prID != "" {
+		mapping, exists, err := app.lookupThreadMapping(ctx, prID)
+		if err != nil {
+			applogger.Log.Error("Failed to inspect thread mapping", "prID", prID, "error", err)
+			c.JSON(500, gin.H{"error": "failed to read store"})
+			return
+		}
+		if !exists {
+			c.JSON(404, gin.H{"error": "no thread for PR", "prId": prID})
+			return
+		}
+		c.JSON(200, mapping)
+		return
+	}
+
+	prIDs, err := app.store.List(ctx)
+	if err != nil {
+		applogger.Log.Error("Failed to list thread mappings", "error", err)
+		c.JSON(500, gin.H{"error": "failed to read store"})
+		return
+	}
+
+	mappings := make([]threadMapping, 0, len(prIDs))
+	for _, prID := range prIDs {
+		mapping, exists, err := app.lookupThreadMapping(ctx, prID)
+		if err != nil {
+			applogger.Log.Error("Failed to inspect thread mapping", "prID", prID, "error", err)
+			c.JSON(500, gin.H{"error": "failed to read store"})
+			return
+		}
+		// index 與 key 不同步時（key 已被刪除）略過
+		if exists {
+			mappings = append(mappings, mapping)
+		}
+	}
+	c.JSON(200, gin.H{"count": len(mappings), "threads": mappings})
+}
+
+// lookupThreadMapping 讀取單一 PR 的 thread ID 與 TTL
+func (app *App) lookupThreadMapping(ctx context.Context, prID string) (threadMapping, bool, error) {
+	threadID, exists, err := app.store.Get(ctx, prID)
+	if err != nil || !exists {
+		return threadMapping{}, false, err
+	}
+
+	ttl, exists, err := app.store.TTL(ctx, prID)
+	if err != nil || !exists {
+		return threadMapping{}, false, err
+	}
+
+	return threadMapping{
+		PRID:       prID,
+		ThreadID:   threadID,
+		Closed:     ttl > 0,
+		TTLSeconds: int64(ttl.Seconds()),
+	}, true, nil
+}
+
+// notificationType 把 GitHub event/action 對應到 EVENTS_ENABLED 的通知類型，不屬於任何類型時回傳空字串
+func notificationType(ghEvent string, payload *github.WebhookPayload) string {
+	switch ghEvent {
+	case "pull_request":
+		switch payload.Action {
+		case "opened":
+			return config.EventOpened
+		case "synchronize":
+			return config.EventUpdated
+		case "closed":
+			if payload.PullRequest != nil && payload.PullRequest.Merged {
+				return config.EventMerged
+			}
+			return config.EventClosed
+		case "reopened":
+			return config.EventReopened
+		case "review_requested":
+			return config.EventReviewRequested
+		case "assigned", "unassigned":
+			return config.EventAssigned
+		}
+	case "pull_request_review":
+		return config.EventReview
+	case "pull_request_review_comment":
+		return config.EventReviewComment
+	}
+	return ""
+}
+
+// getOrCreateThread 取得 PR 對應的 thread，不存在時自動建立
+// 作者被 IGNORED_AUTHORS 排除建立 thread 時回傳 ok=false，呼叫端應直接略過通知
+// （已存在的 thread 不受影響，merge/close 仍會正常 archive）
+func (app *App) getOrCreateThread(ctx context.Context, prID string, pr *github.PullRequest, repoFullName string) (threadID string, ok bool, err error) {
+	threadID, exists, err := app.store.Get(ctx, prID)
+	if err != nil {
+		return "", false, err
+	}
+	if exists {
+		return threadID, true, nil
+	}
+
+	if config.AppConfig.AuthorIgnored(pr.User.Login, config.EventOpened) {
+		applogger.Log.Info("Thread not found and author is ignored, skipping", "prID", prID, "author", pr.User.Login)
+		return "", false, nil
+	}
+
+	applogger.Log.Info("Thread not found, auto-creating", "prID", prID)
+	if err := app.handlePROpened(ctx, prID, pr, repoFullName); err != nil {
+		return "", false, fmt.Errorf("failed to auto-create thread: %w", err)
+	}
+	threadID, exists, err = app.store.Get(ctx, prID)
+	if err != nil || !exists {
+		return "", false, fmt.Errorf("failed to get thread after creation")
+	}
+	return threadID, true, nil
+}
+
+func (app *App) handlePROpened(ctx context.Context, prID string, pr *github.PullRequest, repoFullName string) error {
 	log := applogger.Log
 
-	if existingThreadID, exists, _ := app.store.Get(prID); exists {
+	if config.AppConfig.AuthorIgnored(pr.User.Login, config.EventOpened) {
+		log.Info("Author is ignored, not creating thread", "prID", prID, "author", pr.User.Login)
+		return nil
+	}
+
+	// GitHub 重送與原始請求可能同時到達，持有 lock 才檢查與建立，確保只建立一個 thread
+	unlock, err := app.lockThreadCreation(ctx, prID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if existingThreadID, exists, _ := app.store.Get(ctx, prID); exists {
 		log.Info("Thread already exists", "prID", prID, "threadID", existingThreadID)
 		return nil
 	}
 
 	title := discord.FormatThreadTitle(pr.Number, pr.Title, repoFullName)
-	message := discord.FormatPROpened(pr)
+	message := discord.FormatPROpened(pr, app.listChangedFiles(repoFullName, pr.Number))
 
 	// 取得或建立 repo 對應的 forum tag
 	repoName := repoFullName
@@ -202,49 +745,217 @@ func (app *App) handlePROpened(prID string, pr *github.PullRequest, repoFullName
 	}
 
 	var tagIDs []string
-	if tagID, err := app.discordClient.GetOrCreateRepoTag(repoName); err != nil {
-		log.Warn("Failed to get/create repo tag, creating thread without tag", "repo", repoName, "error", err)
-	} else {
-		tagIDs = append(tagIDs, tagID)
+	if tagger, ok := app.notifier.(notifier.RepoTagger); ok {
+		if tagID, err := tagger.GetOrCreateRepoTag(ctx, repoName); err != nil {
+			log.Warn("Failed to get/create repo tag, creating thread without tag", "repo", repoName, "error", err)
+		} else {
+			tagIDs = append(tagIDs, tagID)
+		}
 	}
 
-	threadID, err := app.discordClient.CreateThread(title, message, tagIDs...)
+	threadID, err := app.notifier.CreateThread(ctx, title, message, tagIDs...)
 	if err != nil {
 		return fmt.Errorf("failed to create thread: %w", err)
 	}
 
-	if err := app.store.Set(prID, threadID); err != nil {
+	if err := app.store.Set(ctx, prID, threadID); err != nil {
 		return fmt.Errorf("failed to save mapping: %w", err)
 	}
 
 	log.Info("Created thread", "prID", prID, "threadID", threadID)
+	app.updateStatus(ctx, prID, threadID, pr, discord.OpenStatus(pr))
+	app.postBackToGitHub(ctx, prID, threadID, pr.Number, repoFullName)
 	return nil
 }
 
-func (app *App) handlePRUpdated(prID string, pr *github.PullRequest, repoFullName string) error {
+// postBackToGitHub 在 PR 下留言附上 Discord thread 連結（POST_BACK_TO_GITHUB 開啟時）
+// 失敗只記錄 log，不影響 thread 建立；token 沒有權限（403）時提示需要的權限
+func (app *App) postBackToGitHub(ctx context.Context, prID, threadID string, prNumber int, repoFullName string) {
+	if !config.AppConfig.PostBackToGitHub || app.githubClient == nil {
+		return
+	}
+	linker, ok := app.notifier.(notifier.ThreadLinker)
+	if !ok {
+		return
+	}
+
 	log := applogger.Log
+	threadURL, err := linker.ThreadURL(ctx, threadID)
+	if err != nil {
+		log.Warn("Failed to build thread link, not commenting on PR", "prID", prID, "threadID", threadID, "error", err)
+		return
+	}
+
+	body := fmt.Sprintf("💬 Discussion thread created in Discord: %s", threadURL)
+	if err := app.githubClient.CreateIssueComment(repoFullName, prNumber, body); err != nil {
+		if github.IsForbidden(err) {
+			log.Warn("GITHUB_TOKEN cannot comment on PR, check that it has write access to pull requests", "prID", prID, "error", err)
+			return
+		}
+		log.Error("Failed to comment thread link on PR", "prID", prID, "error", err)
+		return
+	}
+	log.Info("Commented thread link on PR", "prID", prID, "threadURL", threadURL)
+}
 
-	threadID, exists, err := app.store.Get(prID)
+// updateStatus 更新 thread 中的 PR 狀態訊息（STATUS_MESSAGE 開啟且後端支援編輯時）
+// 還沒有狀態訊息、或已被手動刪除（404）時發送新的一則並記錄 message ID
+// 失敗只記錄 log，不影響事件本身的通知
+func (app *App) updateStatus(ctx context.Context, prID, threadID string, pr *github.PullRequest, status discord.PRStatus) {
+	if !config.AppConfig.StatusMessage {
+		return
+	}
+	editor, ok := app.notifier.(notifier.StatusEditor)
+	if !ok {
+		return
+	}
+
+	log := applogger.Log
+	message := discord.FormatPRStatus(pr, status)
+
+	messageID, exists, err := app.store.GetStatusMessage(ctx, prID)
+	if err != nil {
+		log.Error("Failed to get status message", "prID", prID, "error", err)
+		return
+	}
+
+	if exists {
+		err := editor.EditMessage(ctx, threadID, messageID, message)
+		if err == nil {
+			return
+		}
+		if !discord.IsNotFound(err) {
+			log.Error("Failed to update status message", "prID", prID, "messageID", messageID, "error", err)
+			return
+		}
+		log.Warn("Status message was deleted, posting a new one", "prID", prID, "messageID", messageID)
+	}
+
+	messageID, err = editor.CreateMessage(ctx, threadID, message)
 	if err != nil {
+		log.Error("Failed to post status message", "prID", prID, "threadID", threadID, "error", err)
+		return
+	}
+	if err := app.store.SetStatusMessage(ctx, prID, messageID); err != nil {
+		log.Error("Failed to save status message", "prID", prID, "messageID", messageID, "error", err)
+	}
+}
+
+// checkMergeableState 記錄 PR 的 mergeable_state，進入 dirty（有 conflict）時提醒作者、離開時通知已解決
+// GitHub 還沒算好（unknown）時不處理；沒有 thread 時也不記錄，等之後的事件再判斷
+// 失敗只記錄 log，不影響事件本身的通知
+func (app *App) checkMergeableState(ctx context.Context, prID string, pr *github.PullRequest, repoFullName string) {
+	if !pr.MergeableStateKnown() || !config.AppConfig.EventEnabled(config.EventConflict) {
+		return
+	}
+	if app.digest != nil && config.AppConfig.DigestEnabled(repoFullName) {
+		return
+	}
+
+	log := applogger.Log
+	threadID, exists, err := app.store.Get(ctx, prID)
+	if err != nil || !exists {
+		return
+	}
+
+	previous, err := app.store.SwapMergeableState(ctx, prID, pr.MergeableState)
+	if err != nil {
+		log.Error("Failed to record mergeable state", "prID", prID, "error", err)
+		return
+	}
+
+	wasDirty := previous == github.MergeableStateDirty
+	isDirty := pr.MergeableState == github.MergeableStateDirty
+	var message discord.ThreadMessage
+	switch {
+	case isDirty && !wasDirty:
+		message = discord.FormatMergeConflict(pr, config.AppConfig.UserMapFor(repoFullName))
+	case wasDirty && !isDirty:
+		message = discord.FormatMergeConflictResolved(pr)
+	default:
+		return
+	}
+
+	if err := app.notifier.PostMessage(ctx, threadID, message); err != nil {
+		log.Error("Failed to post merge conflict notification", "prID", prID, "mergeableState", pr.MergeableState, "error", err)
+		return
+	}
+	log.Info("Mergeable state changed", "prID", prID, "from", previous, "to", pr.MergeableState)
+}
+
+// handleDraftChanged draft / ready for review 切換時只更新狀態訊息，不另外發送通知
+func (app *App) handleDraftChanged(ctx context.Context, prID string, pr *github.PullRequest) error {
+	threadID, exists, err := app.store.Get(ctx, prID)
+	if err != nil || !exists {
 		return err
 	}
 
-	if !exists {
-		log.Info("Thread not found, auto-creating", "prID", prID)
-		if err := app.handlePROpened(prID, pr, repoFullName); err != nil {
-			return fmt.Errorf("failed to auto-create thread: %w", err)
+	app.updateStatus(ctx, prID, threadID, pr, discord.OpenStatus(pr))
+	return nil
+}
+
+// lockThreadCreation 取得建立 thread 的 lock，被其他請求持有時等待對方完成
+// 等到之後 handlePROpened 重新讀取 mapping，就會看到對方建立的 thread
+func (app *App) lockThreadCreation(ctx context.Context, prID string) (func(), error) {
+	deadline := time.Now().Add(threadLockTTL)
+	for {
+		acquired, unlock, err := app.store.Lock(ctx, "thread:"+prID, threadLockTTL)
+		if err != nil {
+			return nil, err
 		}
-		threadID, exists, err = app.store.Get(prID)
-		if err != nil || !exists {
-			return fmt.Errorf("failed to get thread after creation")
+		if acquired {
+			return unlock, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for thread creation lock on %s", prID)
+		}
+		select {
+		case <-time.After(threadLockPollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		}
 	}
+}
+
+// listChangedFiles 取得 PR 前幾個變更檔案；沒有 GitHub token 或 API 失敗時回傳 nil（不影響建立 thread）
+func (app *App) listChangedFiles(repoFullName string, prNumber int) []string {
+	if app.githubClient == nil {
+		return nil
+	}
+
+	files, err := app.githubClient.ListChangedFiles(repoFullName, prNumber, maxListedFiles)
+	if err != nil {
+		applogger.Log.Warn("Failed to list changed files", "repo", repoFullName, "prNumber", prNumber, "error", err)
+		return nil
+	}
+	return files
+}
+
+// listFailedJobs 取得 workflow run 失敗的 job；沒有 GitHub token 或 API 失敗時回傳 nil（照常發送通知）
+func (app *App) listFailedJobs(repoFullName string, runID int) []github.WorkflowJob {
+	if app.githubClient == nil {
+		return nil
+	}
+
+	jobs, err := app.githubClient.ListFailedJobs(repoFullName, runID)
+	if err != nil {
+		applogger.Log.Warn("Failed to list failed jobs", "repo", repoFullName, "runID", runID, "error", err)
+		return nil
+	}
+	return jobs
+}
+
+func (app *App) handlePRUpdated(ctx context.Context, prID string, pr *github.PullRequest, repoFullName string) error {
+	threadID, ok, err := app.getOrCreateThread(ctx, prID, pr, repoFullName)
+	if err != nil || !ok {
+		return err
+	}
 
 	message := discord.FormatPRUpdated(pr)
-	return app.discordClient.PostMessage(threadID, message)
+	return app.notifier.PostMessage(ctx, threadID, message)
 }
 
-func (app *App) handleReviewRequested(prID string, pr *github.PullRequest, reviewer *github.User, requestedBy string, repoFullName string) error {
+func (app *App) handleReviewRequested(ctx context.Context, prID string, pr *github.PullRequest, reviewer *github.User, requestedBy string, repoFullName string) error {
 	log := applogger.Log
 
 	if reviewer == nil {
@@ -252,78 +963,166 @@ func (app *App) handleReviewRequested(prID string, pr *github.PullRequest, revie
 		return nil
 	}
 
-	threadID, exists, err := app.store.Get(prID)
-	if err != nil {
+	threadID, ok, err := app.getOrCreateThread(ctx, prID, pr, repoFullName)
+	if err != nil || !ok {
 		return err
 	}
 
-	if !exists {
-		log.Info("Thread not found, auto-creating", "prID", prID)
-		if err := app.handlePROpened(prID, pr, repoFullName); err != nil {
-			return fmt.Errorf("failed to auto-create thread: %w", err)
-		}
-		threadID, exists, err = app.store.Get(prID)
-		if err != nil || !exists {
-			return fmt.Errorf("failed to get thread after creation")
+	// 每位 reviewer 各自追蹤 review round：上一輪要求修改的 reviewer 改送「請重新 review」
+	record, hasRecord, err := app.store.GetReview(ctx, prID, reviewer.Login)
+	if err != nil {
+		log.Error("Failed to get review state", "prID", prID, "reviewer", reviewer.Login, "error", err)
+	}
+
+	var message discord.ThreadMessage
+	if hasRecord && record.State == "changes_requested" {
+		record.Round++
+		message = discord.FormatReReviewRequested(reviewer, requestedBy, record.Round, pr.Number, pr.HTMLURL, config.AppConfig.UserMapFor(repoFullName))
+	} else {
+		if !hasRecord {
+			record.Round = 1
 		}
+		message = discord.FormatReviewRequested(reviewer, requestedBy, pr.Number, pr.HTMLURL, config.AppConfig.UserMapFor(repoFullName))
+	}
+
+	if err := app.notifier.PostMessage(ctx, threadID, message); err != nil {
+		return err
 	}
+	app.updateStatus(ctx, prID, threadID, pr, discord.StatusReviewRequested)
 
-	message := discord.FormatReviewRequested(reviewer, requestedBy, pr.Number, pr.HTMLURL, config.AppConfig.GitHubDiscordUserMap)
-	return app.discordClient.PostMessage(threadID, message)
+	record.State = "pending"
+	if err := app.store.SetReview(ctx, prID, reviewer.Login, record); err != nil {
+		log.Error("Failed to save review state", "prID", prID, "reviewer", reviewer.Login, "error", err)
+	}
+	return nil
 }
 
-func (app *App) handlePRReviewed(prID string, pr *github.PullRequest, review *github.Review, repoFullName string) error {
-	log := applogger.Log
+// handleAssignment 指派 / 取消指派時在 thread 發送通知
+// 同時指派多人時 GitHub 會逐一送出 assigned，每則訊息只對應 payload 中的一位 assignee
+func (app *App) handleAssignment(ctx context.Context, prID string, pr *github.PullRequest, assignee *github.User, sender string, assigned bool, repoFullName string) error {
+	if assignee == nil {
+		applogger.Log.Warn("No assignee in payload", "prID", prID)
+		return nil
+	}
 
-	threadID, exists, err := app.store.Get(prID)
-	if err != nil {
+	threadID, ok, err := app.getOrCreateThread(ctx, prID, pr, repoFullName)
+	if err != nil || !ok {
 		return err
 	}
 
-	if !exists {
-		log.Info("Thread not found, auto-creating", "prID", prID)
-		if err := app.handlePROpened(prID, pr, repoFullName); err != nil {
-			return fmt.Errorf("failed to auto-create thread: %w", err)
-		}
-		threadID, exists, err = app.store.Get(prID)
-		if err != nil || !exists {
-			return fmt.Errorf("failed to get thread after creation")
-		}
+	var message discord.ThreadMessage
+	if assigned {
+		message = discord.FormatAssigned(assignee, sender, pr.Number, pr.HTMLURL, config.AppConfig.UserMapFor(repoFullName))
+	} else {
+		message = discord.FormatUnassigned(assignee, sender, pr.Number, pr.HTMLURL)
 	}
 
-	message := discord.FormatPRReview(review, pr.Number, pr.HTMLURL, pr.User.Login, config.AppConfig.GitHubDiscordUserMap)
-	return app.discordClient.PostMessage(threadID, message)
+	return app.notifier.PostMessage(ctx, threadID, message)
 }
 
-func (app *App) handlePRMerged(prID string, pr *github.PullRequest, mergedBy string, repoFullName string) error {
+func (app *App) handlePRReviewed(ctx context.Context, prID string, pr *github.PullRequest, review *github.Review, repoFullName string) error {
 	log := applogger.Log
 
-	threadID, exists, err := app.store.Get(prID)
+	threadID, ok, err := app.getOrCreateThread(ctx, prID, pr, repoFullName)
+	if err != nil || !ok {
+		return err
+	}
+
+	// 以回覆 PR Opened 訊息的形式發送，讓 review 與 PR 的脈絡串在一起
+	message := discord.FormatPRReview(review, pr.Number, pr.HTMLURL, pr.User.Login, config.AppConfig.UserMapFor(repoFullName))
+	if err := app.notifier.PostMessage(ctx, threadID, message.ReplyTo(threadID)); err != nil {
+		return err
+	}
+
+	// 單純留言不改變 PR 狀態
+	switch strings.ToLower(review.State) {
+	case "approved":
+		app.updateStatus(ctx, prID, threadID, pr, discord.StatusApproved)
+	case "changes_requested":
+		app.updateStatus(ctx, prID, threadID, pr, discord.StatusChangesRequested)
+	}
+
+	// 記錄這位 reviewer 本輪的結果，下次被 re-request 時判斷要不要送「請重新 review」
+	record, hasRecord, err := app.store.GetReview(ctx, prID, review.User.Login)
 	if err != nil {
+		log.Error("Failed to get review state", "prID", prID, "reviewer", review.User.Login, "error", err)
+	}
+	if !hasRecord {
+		record.Round = 1
+	}
+	// 跟 GitHub 一樣，單純留言不會解除先前的 changes_requested
+	state := strings.ToLower(review.State)
+	if !(state == "commented" && record.State == "changes_requested") {
+		record.State = state
+	}
+	if err := app.store.SetReview(ctx, prID, review.User.Login, record); err != nil {
+		log.Error("Failed to save review state", "prID", prID, "reviewer", review.User.Login, "error", err)
+	}
+	return nil
+}
+
+// handleReviewComment 行內留言時在 thread 發送留言內容與被留言的 diff 片段
+func (app *App) handleReviewComment(ctx context.Context, prID string, pr *github.PullRequest, comment *github.ReviewComment, repoFullName string) error {
+	if comment == nil {
+		applogger.Log.Warn("No comment in payload", "prID", prID)
+		return nil
+	}
+
+	threadID, ok, err := app.getOrCreateThread(ctx, prID, pr, repoFullName)
+	if err != nil || !ok {
 		return err
 	}
 
-	if !exists {
-		log.Info("Thread not found, auto-creating before merge notification", "prID", prID)
-		if err := app.handlePROpened(prID, pr, repoFullName); err != nil {
-			return fmt.Errorf("failed to auto-create thread: %w", err)
-		}
-		threadID, exists, err = app.store.Get(prID)
-		if err != nil || !exists {
-			return fmt.Errorf("failed to get thread after creation")
-		}
+	message := discord.FormatReviewComment(comment, pr.Number, pr.HTMLURL)
+	return app.notifier.PostMessage(ctx, threadID, message)
+}
+
+// postOrRecreate 發送訊息到 PR thread；若 thread 已被手動刪除（404）就重建後再送一次
+// 回傳實際使用的 thread ID，後續 archive 要用新的 ID
+func (app *App) postOrRecreate(ctx context.Context, prID, threadID string, pr *github.PullRequest, repoFullName string, message discord.ThreadMessage) (string, error) {
+	err := app.notifier.PostMessage(ctx, threadID, message)
+	if err == nil || !discord.IsNotFound(err) {
+		return threadID, err
+	}
+
+	applogger.Log.Warn("Thread was deleted, recreating", "prID", prID, "threadID", threadID)
+	if err := app.store.Delete(ctx, prID); err != nil {
+		return "", fmt.Errorf("failed to delete stale thread mapping: %w", err)
+	}
+	if err := app.handlePROpened(ctx, prID, pr, repoFullName); err != nil {
+		return "", fmt.Errorf("failed to recreate thread: %w", err)
+	}
+
+	newThreadID, exists, err := app.store.Get(ctx, prID)
+	if err != nil || !exists {
+		return "", fmt.Errorf("failed to get thread after recreation")
+	}
+
+	return newThreadID, app.notifier.PostMessage(ctx, newThreadID, message)
+}
+
+func (app *App) handlePRMerged(ctx context.Context, prID string, pr *github.PullRequest, mergedBy string, repoFullName string) error {
+	log := applogger.Log
+
+	threadID, ok, err := app.getOrCreateThread(ctx, prID, pr, repoFullName)
+	if err != nil || !ok {
+		return err
 	}
 
 	message := discord.FormatPRMerged(pr, mergedBy)
-	if err := app.discordClient.PostMessage(threadID, message); err != nil {
+	threadID, err = app.postOrRecreate(ctx, prID, threadID, pr, repoFullName, message)
+	if err != nil {
 		return err
 	}
 
-	if err := app.discordClient.ArchiveThread(threadID); err != nil {
+	// archive 之後無法再編輯訊息，要先更新狀態
+	app.updateStatus(ctx, prID, threadID, pr, discord.StatusMerged)
+
+	if err := app.notifier.ArchiveThread(ctx, threadID); err != nil {
 		log.Error("Failed to archive thread", "prID", prID, "threadID", threadID, "error", err)
 	}
 
-	if err := app.store.MarkAsClosed(prID); err != nil {
+	if err := app.store.MarkAsClosed(ctx, prID); err != nil {
 		log.Error("Failed to mark as closed", "prID", prID, "error", err)
 	}
 
@@ -331,35 +1130,28 @@ func (app *App) handlePRMerged(prID string, pr *github.PullRequest, mergedBy str
 	return nil
 }
 
-func (app *App) handlePRClosed(prID string, pr *github.PullRequest, closedBy string, repoFullName string) error {
+func (app *App) handlePRClosed(ctx context.Context, prID string, pr *github.PullRequest, closedBy string, repoFullName string) error {
 	log := applogger.Log
 
-	threadID, exists, err := app.store.Get(prID)
-	if err != nil {
+	threadID, ok, err := app.getOrCreateThread(ctx, prID, pr, repoFullName)
+	if err != nil || !ok {
 		return err
 	}
 
-	if !exists {
-		log.Info("Thread not found, auto-creating before close notification", "prID", prID)
-		if err := app.handlePROpened(prID, pr, repoFullName); err != nil {
-			return fmt.Errorf("failed to auto-create thread: %w", err)
-		}
-		threadID, exists, err = app.store.Get(prID)
-		if err != nil || !exists {
-			return fmt.Errorf("failed to get thread after creation")
-		}
-	}
-
 	message := discord.FormatPRClosed(pr, closedBy)
-	if err := app.discordClient.PostMessage(threadID, message); err != nil {
+	threadID, err = app.postOrRecreate(ctx, prID, threadID, pr, repoFullName, message)
+	if err != nil {
 		return err
 	}
 
-	if err := app.discordClient.ArchiveThread(threadID); err != nil {
+	// archive 之後無法再編輯訊息，要先更新狀態
+	app.updateStatus(ctx, prID, threadID, pr, discord.StatusClosed)
+
+	if err := app.notifier.ArchiveThread(ctx, threadID); err != nil {
 		log.Error("Failed to archive thread", "prID", prID, "threadID", threadID, "error", err)
 	}
 
-	if err := app.store.MarkAsClosed(prID); err != nil {
+	if err := app.store.MarkAsClosed(ctx, prID); err != nil {
 		log.Error("Failed to mark as closed", "prID", prID, "error", err)
 	}
 
@@ -367,14 +1159,14 @@ func (app *App) handlePRClosed(prID string, pr *github.PullRequest, closedBy str
 	return nil
 }
 
-func (app *App) handlePRReopened(prID string, pr *github.PullRequest, repoFullName string) error {
-	threadID, exists, err := app.store.Get(prID)
+func (app *App) handlePRReopened(ctx context.Context, prID string, pr *github.PullRequest, repoFullName string) error {
+	threadID, exists, err := app.store.Get(ctx, prID)
 	if err != nil {
 		return err
 	}
 
 	if !exists {
-		return app.handlePROpened(prID, pr, repoFullName)
+		return app.handlePROpened(ctx, prID, pr, repoFullName)
 	}
 
 	message := discord.ThreadMessage{
@@ -383,15 +1175,56 @@ func (app *App) handlePRReopened(prID string, pr *github.PullRequest, repoFullNa
 				Title:       "🔄 PR Reopened",
 				Description: fmt.Sprintf("**%s** has been reopened", pr.Title),
 				URL:         pr.HTMLURL,
-				Color:       discord.ColorYellow,
+				Color:       discord.CurrentTheme().Review,
 			},
 		},
 	}
 
-	return app.discordClient.PostMessage(threadID, message)
+	// 發送訊息會讓 archived thread 重新開啟，之後才能編輯狀態訊息
+	if err := app.notifier.PostMessage(ctx, threadID, message); err != nil {
+		return err
+	}
+	app.updateStatus(ctx, prID, threadID, pr, discord.OpenStatus(pr))
+	return nil
 }
 
-func (app *App) handleWorkflowRunCompleted(payload *github.WebhookPayload) error {
+// handlePREdited PR 標題修改時同步更新 thread 標題，其他欄位的修改不通知
+func (app *App) handlePREdited(ctx context.Context, prID string, pr *github.PullRequest, changes *github.Changes, repoFullName string) error {
+	log := applogger.Log
+
+	if changes == nil || changes.Title == nil || changes.Title.From == pr.Title {
+		return nil
+	}
+
+	renamer, ok := app.notifier.(notifier.ThreadRenamer)
+	if !ok {
+		return nil
+	}
+
+	threadID, exists, err := app.store.Get(ctx, prID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Info("No thread for edited PR, skipping rename", "prID", prID)
+		return nil
+	}
+
+	oldTitle := discord.FormatThreadTitle(pr.Number, changes.Title.From, repoFullName)
+	newTitle := discord.FormatThreadTitle(pr.Number, pr.Title, repoFullName)
+	if oldTitle == newTitle {
+		return nil
+	}
+
+	if err := renamer.RenameThread(ctx, threadID, newTitle); err != nil {
+		return fmt.Errorf("failed to rename thread: %w", err)
+	}
+
+	log.Info("Thread renamed", "prID", prID, "threadID", threadID, "from", oldTitle, "to", newTitle)
+	return nil
+}
+
+func (app *App) handleWorkflowRunCompleted(ctx context.Context, payload *github.WebhookPayload) error {
 	log := applogger.Log
 
 	wr := payload.WorkflowRun
@@ -400,17 +1233,57 @@ func (app *App) handleWorkflowRunCompleted(payload *github.WebhookPayload) error
 		return nil
 	}
 
-	// 只通知 success 和 failure，其他（cancelled、timed_out 等）不發送
-	if wr.Conclusion != "success" && wr.Conclusion != "failure" {
+	// 預設只通知 success 和 failure，其他（cancelled、timed_out 等）可用 CI_CONCLUSIONS 開啟
+	if !config.AppConfig.CINotify(wr.Conclusion) {
 		log.Info("Skipping CI notification", "conclusion", wr.Conclusion, "workflow", wr.Name)
 		return nil
 	}
 
+	if app.digest != nil && config.AppConfig.DigestEnabled(payload.Repository.FullName) {
+		return nil
+	}
+
+	// fork PR 的 workflow 權限受限、失敗多半是雜訊；opened / merged 等非 CI 事件照常通知
+	if config.AppConfig.SkipForkCI && wr.IsFromFork(payload.Repository.FullName) {
+		log.Info("Workflow run is from a fork, skipping CI notification", "headRepo", wr.HeadRepository.FullName, "workflow", wr.Name)
+		return nil
+	}
+
+	if config.AppConfig.AuthorIgnored(wr.Actor.Login, config.EventCI) {
+		log.Info("Workflow actor is ignored, skipping CI notification", "actor", wr.Actor.Login, "workflow", wr.Name)
+		return nil
+	}
+
+	// 合併模式：暫存結果，時間窗結束後由 postCISummary 一起發送
+	if app.ciCoalescer != nil {
+		result := storage.CIResult{
+			Workflow:   wr.Name,
+			Conclusion: wr.Conclusion,
+			URL:        wr.HTMLURL,
+			HeadSHA:    wr.HeadSHA,
+			At:         wr.UpdatedAt,
+		}
+		for _, wrPR := range wr.PullRequests {
+			result.PRIDs = append(result.PRIDs, fmt.Sprintf("%s#%d", payload.Repository.FullName, wrPR.Number))
+		}
+		if len(result.PRIDs) == 0 {
+			return nil
+		}
+		return app.ciCoalescer.Add(ctx, payload.Repository.FullName+"@"+wr.HeadSHA, result)
+	}
+
+	// 失敗時列出失敗的 job（需要 GitHub token），所有關聯 PR 共用同一份訊息
+	var failedJobs []github.WorkflowJob
+	if wr.Conclusion == "failure" && len(wr.PullRequests) > 0 {
+		failedJobs = app.listFailedJobs(payload.Repository.FullName, wr.ID)
+	}
+	message := discord.FormatWorkflowRunResult(wr, config.AppConfig.CIConclusionStyle(wr.Conclusion), failedJobs)
+
 	// 只通知有關聯 PR 的 workflow run
 	for _, wrPR := range wr.PullRequests {
 		prID := fmt.Sprintf("%s#%d", payload.Repository.FullName, wrPR.Number)
 
-		threadID, exists, err := app.store.Get(prID)
+		threadID, exists, err := app.store.Get(ctx, prID)
 		if err != nil {
 			log.Error("Failed to get thread", "prID", prID, "error", err)
 			continue
@@ -420,8 +1293,7 @@ func (app *App) handleWorkflowRunCompleted(payload *github.WebhookPayload) error
 			continue
 		}
 
-		message := discord.FormatWorkflowRunResult(wr)
-		if err := app.discordClient.PostMessage(threadID, message); err != nil {
+		if err := app.notifier.PostMessage(ctx, threadID, message.ReplyTo(threadID)); err != nil {
 			log.Error("Failed to post CI notification", "prID", prID, "error", err)
 		}
 	}
@@ -429,15 +1301,64 @@ func (app *App) handleWorkflowRunCompleted(payload *github.WebhookPayload) error
 	return nil
 }
 
-func verifySignature(payload []byte, signature, secret string) bool {
-	if secret == "" {
-		return true
+// postCISummary 把同一個 commit 合併後的 CI 結果發送到所有關聯 PR 的 thread
+func (app *App) postCISummary(ctx context.Context, results []storage.CIResult, followUp bool) {
+	log := applogger.Log
+	runs := make([]discord.CIRunResult, 0, len(results))
+	for _, r := range results {
+		runs = append(runs, discord.CIRunResult{Workflow: r.Workflow, Conclusion: r.Conclusion, URL: r.URL, HeadSHA: r.HeadSHA, At: r.At})
 	}
+	message := discord.FormatCISummary(runs, config.AppConfig.CIConclusionStyle, followUp)
+
+	seen := make(map[string]bool)
+	for _, result := range results {
+		for _, prID := range result.PRIDs {
+			if seen[prID] {
+				continue
+			}
+			seen[prID] = true
+
+			threadID, exists, err := app.store.Get(ctx, prID)
+			if err != nil {
+				log.Error("Failed to get thread", "prID", prID, "error", err)
+				continue
+			}
+			if !exists {
+				log.Info("No thread for PR, skipping CI notification", "prID", prID)
+				continue
+			}
 
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(payload)
-	expectedMAC := hex.EncodeToString(mac.Sum(nil))
-	expectedSignature := "sha256=" + expectedMAC
+			if err := app.notifier.PostMessage(ctx, threadID, message.ReplyTo(threadID)); err != nil {
+				log.Error("Failed to post CI summary", "prID", prID, "error", err)
+			}
+		}
+	}
+}
+
+func (app *App) handleReleasePublished(ctx context.Context, payload *github.WebhookPayload) error {
+	log := applogger.Log
 
-	return hmac.Equal([]byte(signature), []byte(expectedSignature))
+	release := payload.Release
+	if release == nil {
+		log.Warn("No release in payload")
+		return nil
+	}
+
+	if release.Draft {
+		log.Info("Skipping draft release", "tag", release.TagName)
+		return nil
+	}
+
+	if app.announceChannel == "" {
+		log.Info("No announcements channel configured, skipping release", "tag", release.TagName)
+		return nil
+	}
+
+	message := discord.FormatRelease(release, payload.Repository.FullName)
+	if err := app.notifier.PostMessage(ctx, app.announceChannel, message); err != nil {
+		return fmt.Errorf("failed to post release announcement: %w", err)
+	}
+
+	log.Info("Release announced", "repo", payload.Repository.FullName, "tag", release.TagName)
+	return nil
 }
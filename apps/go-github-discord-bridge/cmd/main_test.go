@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"dizzycode1112/github-discord-bridge/internal/config"
+	"dizzycode1112/github-discord-bridge/internal/discord"
+	"dizzycode1112/github-discord-bridge/internal/github"
+	"dizzycode1112/github-discord-bridge/internal/middleware"
+	"dizzycode1112/github-discord-bridge/internal/storage"
+	"dizzycode1112/github-discord-bridge/pkg/applogger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testPRID testdata 中 PR 的 identifier
+const testPRID = "acme/bridge#7"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	applogger.Log = nopLogger{}
+}
+
+// nopLogger 丟掉所有 log，避免測試輸出被 handler 的 log 淹沒
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, context ...any)  {}
+func (nopLogger) Error(msg string, context ...any) {}
+func (nopLogger) Warn(msg string, context ...any)  {}
+func (nopLogger) Debug(msg string, context ...any) {}
+func (nopLogger) Flush() error                     { return nil }
+
+// notifierCall fakeNotifier 收到的一次呼叫
+type notifierCall struct {
+	Method   string
+	ThreadID string
+}
+
+// fakeNotifier 記錄所有呼叫的 notifier.Notifier，CreateThread 依序回傳 thread-1、thread-2…
+// deleted 中的 thread 在 PostMessage 時回 404，模擬被手動刪除的 thread
+type fakeNotifier struct {
+	mu      sync.Mutex
+	calls   []notifierCall
+	created int
+	deleted map[string]bool
+}
+
+func (n *fakeNotifier) record(method, threadID string) {
+	n.calls = append(n.calls, notifierCall{Method: method, ThreadID: threadID})
+}
+
+func (n *fakeNotifier) CreateThread(ctx context.Context, title string, message discord.ThreadMessage, tagIDs ...string) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.created++
+	threadID := fmt.Sprintf("thread-%d", n.created)
+	n.record("CreateThread", threadID)
+	return threadID, nil
+}
+
+func (n *fakeNotifier) PostMessage(ctx context.Context, threadID string, message discord.ThreadMessage) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.record("PostMessage", threadID)
+	if n.deleted[threadID] {
+		return &discord.DiscordAPIError{StatusCode: http.StatusNotFound, Code: 10003, Message: "Unknown Channel"}
+	}
+	return nil
+}
+
+func (n *fakeNotifier) ArchiveThread(ctx context.Context, threadID string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.record("ArchiveThread", threadID)
+	return nil
+}
+
+// Calls 回傳到目前為止的呼叫
+func (n *fakeNotifier) Calls() []notifierCall {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]notifierCall(nil), n.calls...)
+}
+
+// testApp 以 MemoryStore 與 fakeNotifier 組成的 App，經過與正式環境相同的 webhook middleware
+type testApp struct {
+	*App
+	store    *storage.MemoryStore
+	notifier *fakeNotifier
+	router   *gin.Engine
+}
+
+// newTestApp 建立 testApp；env 為 KEY=value 形式，覆寫預設的設定
+func newTestApp(t *testing.T, env ...string) *testApp {
+	t.Helper()
+
+	t.Setenv("REDIS_URL", "redis://localhost:6379")
+	t.Setenv("NOTIFIER_BACKEND", "discord")
+	t.Setenv("DISCORD_BOT_TOKEN", "test-token")
+	t.Setenv("DISCORD_FORUM_CHANNEL_ID", "123456789012345678")
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		t.Setenv(key, value)
+	}
+
+	cfg, err := config.Parse()
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	previous := config.AppConfig
+	config.AppConfig = cfg
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	ta := &testApp{
+		store:    storage.NewMemoryStore(),
+		notifier: &fakeNotifier{deleted: make(map[string]bool)},
+	}
+	ta.App = &App{
+		ctx:      context.Background(),
+		store:    ta.store,
+		notifier: ta.notifier,
+	}
+
+	ta.router = gin.New()
+	ta.router.POST("/webhook/github",
+		middleware.GitHubWebhook("", cfg.SignatureSchemes, github.ReplayPolicy{}, cfg.WebhookMaxBodyBytes, nopLogger{}),
+		ta.handleGitHubWebhook,
+	)
+	return ta
+}
+
+// send 以 testdata/<fixture>.json 為 body 送出 ghEvent webhook，回應不是 200 時測試失敗
+func (ta *testApp) send(t *testing.T, ghEvent, fixture string) {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", fixture+".json"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook/github", bytes.NewReader(body))
+	req.Header.Set(middleware.GitHubEventHeader, ghEvent)
+	req.Header.Set(middleware.GitHubDeliveryHeader, "delivery-"+fixture)
+	w := httptest.NewRecorder()
+	ta.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s %s: status = %d, body %s", ghEvent, fixture, w.Code, w.Body.String())
+	}
+}
+
+// seedThread 預先建立 PR 與 thread 的對應，模擬之前已處理過 opened
+func (ta *testApp) seedThread(t *testing.T, threadID string) {
+	t.Helper()
+	if err := ta.store.Set(context.Background(), testPRID, threadID); err != nil {
+		t.Fatalf("failed to seed thread: %v", err)
+	}
+}
+
+// assertCalls 比對 notifier 收到的呼叫
+func (ta *testApp) assertCalls(t *testing.T, want ...notifierCall) {
+	t.Helper()
+	got := ta.notifier.Calls()
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("notifier calls = %+v, want %+v", got, want)
+	}
+}
+
+// assertThread 比對 store 中 PR 對應的 thread 與是否已關閉（有 TTL）
+func (ta *testApp) assertThread(t *testing.T, wantThreadID string, wantClosed bool) {
+	t.Helper()
+	ctx := context.Background()
+	threadID, exists, err := ta.store.Get(ctx, testPRID)
+	if err != nil || !exists || threadID != wantThreadID {
+		t.Fatalf("mapping = %q (exists %v, err %v), want %q", threadID, exists, err, wantThreadID)
+	}
+	ttl, _, _ := ta.store.TTL(ctx, testPRID)
+	if closed := ttl > 0; closed != wantClosed {
+		t.Fatalf("mapping closed = %v (ttl %s), want %v", closed, ttl, wantClosed)
+	}
+}
+
+func TestPullRequestOpenedCreatesThread(t *testing.T) {
+	ta := newTestApp(t)
+
+	ta.send(t, "pull_request", "pull_request_opened")
+
+	ta.assertCalls(t, notifierCall{"CreateThread", "thread-1"})
+	ta.assertThread(t, "thread-1", false)
+}
+
+func TestPullRequestOpenedRedeliveryKeepsOneThread(t *testing.T) {
+	ta := newTestApp(t)
+
+	ta.send(t, "pull_request", "pull_request_opened")
+	ta.send(t, "pull_request", "pull_request_opened")
+
+	ta.assertCalls(t, notifierCall{"CreateThread", "thread-1"})
+}
+
+func TestPullRequestSynchronizeAutoCreatesMissingThread(t *testing.T) {
+	ta := newTestApp(t)
+
+	ta.send(t, "pull_request", "pull_request_synchronize")
+
+	ta.assertCalls(t,
+		notifierCall{"CreateThread", "thread-1"},
+		notifierCall{"PostMessage", "thread-1"},
+	)
+	ta.assertThread(t, "thread-1", false)
+}
+
+func TestPullRequestSynchronizePostsToExistingThread(t *testing.T) {
+	ta := newTestApp(t)
+	ta.seedThread(t, "thread-existing")
+
+	ta.send(t, "pull_request", "pull_request_synchronize")
+
+	ta.assertCalls(t, notifierCall{"PostMessage", "thread-existing"})
+}
+
+func TestPullRequestMergedArchivesAndMarksClosed(t *testing.T) {
+	ta := newTestApp(t)
+	ta.seedThread(t, "thread-existing")
+
+	ta.send(t, "pull_request", "pull_request_closed_merged")
+
+	ta.assertCalls(t,
+		notifierCall{"PostMessage", "thread-existing"},
+		notifierCall{"ArchiveThread", "thread-existing"},
+	)
+	ta.assertThread(t, "thread-existing", true)
+}
+
+func TestPullRequestClosedArchivesAndMarksClosed(t *testing.T) {
+	ta := newTestApp(t)
+	ta.seedThread(t, "thread-existing")
+
+	ta.send(t, "pull_request", "pull_request_closed")
+
+	ta.assertCalls(t,
+		notifierCall{"PostMessage", "thread-existing"},
+		notifierCall{"ArchiveThread", "thread-existing"},
+	)
+	ta.assertThread(t, "thread-existing", true)
+}
+
+func TestPullRequestMergedRecreatesDeletedThread(t *testing.T) {
+	ta := newTestApp(t)
+	ta.seedThread(t, "thread-deleted")
+	ta.notifier.deleted["thread-deleted"] = true
+
+	ta.send(t, "pull_request", "pull_request_closed_merged")
+
+	ta.assertCalls(t,
+		notifierCall{"PostMessage", "thread-deleted"},
+		notifierCall{"CreateThread", "thread-1"},
+		notifierCall{"PostMessage", "thread-1"},
+		notifierCall{"ArchiveThread", "thread-1"},
+	)
+	ta.assertThread(t, "thread-1", true)
+}
+
+func TestWorkflowRunPostsToPRThread(t *testing.T) {
+	ta := newTestApp(t)
+	ta.seedThread(t, "thread-existing")
+
+	ta.send(t, "workflow_run", "workflow_run_completed")
+
+	ta.assertCalls(t, notifierCall{"PostMessage", "thread-existing"})
+}
+
+func TestWorkflowRunWithoutThreadIsSkipped(t *testing.T) {
+	// workflow_run 不走 handleEvent，沒有 thread 時不會自動建立
+	ta := newTestApp(t)
+
+	ta.send(t, "workflow_run", "workflow_run_completed")
+
+	ta.assertCalls(t)
+}
+
+func TestWorkflowRunFromFork(t *testing.T) {
+	tests := []struct {
+		name       string
+		skipForkCI string
+		fixture    string
+		wantPosted bool
+	}{
+		{"same repo with SKIP_FORK_CI", "true", "workflow_run_completed", true},
+		{"cross repo with SKIP_FORK_CI", "true", "workflow_run_completed_fork", false},
+		{"cross repo without SKIP_FORK_CI", "false", "workflow_run_completed_fork", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestApp(t, "SKIP_FORK_CI="+tt.skipForkCI)
+			ta.seedThread(t, "thread-existing")
+
+			ta.send(t, "workflow_run", tt.fixture)
+
+			if tt.wantPosted {
+				ta.assertCalls(t, notifierCall{"PostMessage", "thread-existing"})
+			} else {
+				ta.assertCalls(t)
+			}
+		})
+	}
+}
+
+func TestForkPullRequestOpenedStillCreatesThread(t *testing.T) {
+	// SKIP_FORK_CI 只略過 CI 通知，fork PR 的 opened 照常建立 thread
+	ta := newTestApp(t, "SKIP_FORK_CI=true")
+
+	ta.send(t, "pull_request", "pull_request_opened_fork")
+
+	ta.assertCalls(t, notifierCall{"CreateThread", "thread-1"})
+}
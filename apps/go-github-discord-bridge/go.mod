@@ -6,11 +6,15 @@ require (
 	dizzycoder1112/logger v0.0.0
 	github.com/gin-gonic/gin v1.11.0
 	github.com/joho/godotenv v1.5.1
+	github.com/rabbitmq/amqp091-go v1.10.0
 	github.com/redis/go-redis/v9 v9.17.3
+	weedza.shop/rabbitmq v0.0.0
 )
 
 replace dizzycoder1112/logger => ../../go-packages/logger
 
+replace weedza.shop/rabbitmq => ../../go-packages/rabbitMQ
+
 require (
 	github.com/bytedance/sonic v1.14.0 // indirect
 	github.com/bytedance/sonic/loader v0.3.0 // indirect
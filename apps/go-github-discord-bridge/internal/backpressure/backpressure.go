@@ -0,0 +1,114 @@
+package backpressure
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter 限制同時處理 webhook（呼叫 Discord）的數量，與進來的請求數無關
+// 跟 Discord client 的 rate limiter 不同：rate limiter 限制每秒請求數，Limiter 限制同時進行的 handler 數
+//
+// 等不到空位時，backlog 為 0 由呼叫端直接回 503（shed）；
+// 否則把工作排進 backlog，空出位置後在背景處理（enqueue），backlog 滿了同樣回 503
+type Limiter struct {
+	slots   chan struct{}
+	wait    time.Duration
+	backlog chan func()
+
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup // backlog 中與執行中的背景工作
+}
+
+// New 建立 Limiter：size 為同時處理的上限，wait 為等待空位的時間，backlog 為排隊上限（0 表示不排隊）
+func New(size int, wait time.Duration, backlog int) *Limiter {
+	l := &Limiter{
+		slots: make(chan struct{}, size),
+		wait:  wait,
+	}
+	if backlog > 0 {
+		l.backlog = make(chan func(), backlog)
+		go l.runBacklog()
+	}
+	return l
+}
+
+// Acquire 在 wait 內取得空位，成功時回傳 release（處理完必須呼叫）
+// 等不到或 ctx 結束時 ok 為 false
+func (l *Limiter) Acquire(ctx context.Context) (release func(), ok bool) {
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, true
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+func (l *Limiter) release() {
+	<-l.slots
+}
+
+// Enqueue 把 job 排進 backlog，空出位置後在背景執行
+// 沒有設定 backlog、backlog 已滿或已 Close 時回傳 false
+func (l *Limiter) Enqueue(job func()) bool {
+	if l.backlog == nil {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+
+	l.wg.Add(1)
+	select {
+	case l.backlog <- job:
+		return true
+	default:
+		l.wg.Done()
+		return false
+	}
+}
+
+// runBacklog 依序等空位並執行排隊的工作
+func (l *Limiter) runBacklog() {
+	for job := range l.backlog {
+		l.slots <- struct{}{}
+		go func(job func()) {
+			defer l.wg.Done()
+			defer l.release()
+			job()
+		}(job)
+	}
+}
+
+// Close 停止接受新的排隊工作，等待 backlog 處理完或 ctx 到期（graceful shutdown 時呼叫）
+func (l *Limiter) Close(ctx context.Context) {
+	if l.backlog == nil {
+		return
+	}
+
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.backlog)
+	}
+	l.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,108 @@
+package coalesce
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"dizzycode1112/github-discord-bridge/internal/storage"
+	"dizzycode1112/github-discord-bridge/pkg/applogger"
+)
+
+// resultTTLGrace 暫存結果的 TTL 比時間窗多留的時間，只在負責發送的 instance 掛掉時才會用到
+const resultTTLGrace = time.Minute
+
+// PostFunc 發送同一個 commit 合併後的 CI 結果；followUp 表示時間窗之後才到的結果
+type PostFunc func(ctx context.Context, results []storage.CIResult, followUp bool)
+
+// Coalescer 把同一個 commit（repo + head SHA）在時間窗內完成的 workflow run 合併成一則通知
+//
+// 結果存在 store（多個 instance 共用），收到第一筆的 instance 排程在 window 後發送
+// 時間窗之後才到的結果會開始新的時間窗，發送時標記為補發（follow-up），不會被丟掉
+type Coalescer struct {
+	store  storage.CIStore
+	window time.Duration
+	post   PostFunc
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer // 這個 instance 排程中的 key
+	wg      sync.WaitGroup
+}
+
+// New 建立 Coalescer
+func New(store storage.CIStore, window time.Duration, post PostFunc) *Coalescer {
+	return &Coalescer{
+		store:   store,
+		window:  window,
+		post:    post,
+		pending: make(map[string]*time.Timer),
+	}
+}
+
+// Add 暫存一筆結果，是時間窗的第一筆時排程在 window 後發送
+func (c *Coalescer) Add(ctx context.Context, key string, result storage.CIResult) error {
+	count, err := c.store.AppendCIResult(ctx, key, result, c.window+resultTTLGrace)
+	if err != nil {
+		return err
+	}
+	if count != 1 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, scheduled := c.pending[key]; !scheduled {
+		c.wg.Add(1)
+		c.pending[key] = time.AfterFunc(c.window, func() {
+			defer c.wg.Done()
+			c.flush(key)
+		})
+	}
+	return nil
+}
+
+// flush 取出 key 的所有結果並發送
+func (c *Coalescer) flush(key string) {
+	c.mu.Lock()
+	delete(c.pending, key)
+	c.mu.Unlock()
+
+	ctx := context.Background()
+	results, followUp, err := c.store.TakeCIResults(ctx, key)
+	if err != nil {
+		applogger.Log.Error("Failed to take coalesced CI results", "key", key, "error", err)
+		return
+	}
+	if len(results) == 0 {
+		return
+	}
+	c.post(ctx, results, followUp)
+}
+
+// Close 立即發送這個 instance 排程中的結果（graceful shutdown 時呼叫），等待發送完成或 ctx 到期
+func (c *Coalescer) Close(ctx context.Context) {
+	c.mu.Lock()
+	var keys []string
+	for key, timer := range c.pending {
+		// Stop 成功表示 timer 還沒觸發，由這裡代替它發送
+		if timer.Stop() {
+			keys = append(keys, key)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, key := range keys {
+		c.flush(key)
+		c.wg.Done()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"log"
+	"strings"
+)
+
+// AuthorFilter IGNORED_AUTHORS 中的一條規則
+type AuthorFilter struct {
+	Pattern string          // GitHub login，支援 * 萬用字元，例如 "*[bot]"
+	Events  map[string]bool // 要抑制的通知類型
+}
+
+// defaultIgnoredEvents 規則沒指定類型時抑制的通知：不建立 thread，也不發 CI 結果
+var defaultIgnoredEvents = []string{EventOpened, EventCI}
+
+// AuthorIgnored 回傳 login 的 event 類型通知是否應被抑制
+func (c *Config) AuthorIgnored(login, event string) bool {
+	for _, f := range c.IgnoredAuthors {
+		if f.Events[event] && matchGlob(f.Pattern, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIgnoredAuthors 解析 IGNORED_AUTHORS
+// 格式為逗號分隔的規則，每條規則為 "pattern" 或 "pattern:event|event"，例如
+//
+//	dependabot[bot],renovate[bot]:ci
+//
+// 第一條不建立 thread 也不發 CI；第二條照常建立 thread，只抑制 CI
+func parseIgnoredAuthors(raw string) []AuthorFilter {
+	var filters []AuthorFilter
+	for _, rule := range strings.Split(raw, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		pattern, scope, hasScope := strings.Cut(rule, ":")
+		events := defaultIgnoredEvents
+		if hasScope {
+			events = strings.Split(scope, "|")
+		}
+
+		filter := AuthorFilter{Pattern: strings.TrimSpace(pattern), Events: make(map[string]bool)}
+		for _, event := range events {
+			event = strings.TrimSpace(event)
+			if !isKnownEvent(event) {
+				log.Printf("Warning: unknown event %q in IGNORED_AUTHORS rule %q", event, rule)
+				continue
+			}
+			filter.Events[event] = true
+		}
+		filters = append(filters, filter)
+	}
+	return filters
+}
+
+// matchGlob 比對只支援 * 的萬用字元（login 會包含 [bot]，不能用 path.Match 的字元類別語法）
+func matchGlob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return strings.EqualFold(pattern, s)
+	}
+
+	s = strings.ToLower(s)
+	for i, part := range parts {
+		part = strings.ToLower(part)
+		switch {
+		case i == 0:
+			if !strings.HasPrefix(s, part) {
+				return false
+			}
+			s = s[len(part):]
+		case i == len(parts)-1:
+			return strings.HasSuffix(s, part)
+		default:
+			idx := strings.Index(s, part)
+			if idx < 0 {
+				return false
+			}
+			s = s[idx+len(part):]
+		}
+	}
+	return true
+}
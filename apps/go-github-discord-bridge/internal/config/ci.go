@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"dizzycode1112/github-discord-bridge/internal/discord"
+)
+
+// defaultCINotify 未設定 CI_CONCLUSIONS 時只通知 success 與 failure
+var defaultCINotify = map[string]bool{
+	"success": true,
+	"failure": true,
+}
+
+// ciConclusionOverride CI_CONCLUSIONS 中單一 conclusion 的設定，未填的欄位沿用預設
+type ciConclusionOverride struct {
+	Emoji  *string `json:"emoji"`
+	Title  *string `json:"title"`
+	Color  *int    `json:"color"`
+	Notify *bool   `json:"notify"`
+}
+
+// CIConclusionStyle 回傳 workflow run conclusion 的通知樣式（CI_CONCLUSIONS 覆寫優先，其次是 THEME_COLORS 的配色）
+func (c *Config) CIConclusionStyle(conclusion string) discord.ConclusionStyle {
+	if style, ok := c.CIConclusionStyles[conclusion]; ok {
+		return style
+	}
+	return c.Theme.ConclusionStyle(conclusion)
+}
+
+// CINotify 回傳該 conclusion 是否要發送 CI 通知
+func (c *Config) CINotify(conclusion string) bool {
+	return c.CINotifyConclusions[conclusion]
+}
+
+// parseCIConclusions 解析 CI_CONCLUSIONS（JSON object，key 為 conclusion）
+// 例如 {"failure": {"emoji": ":boom:", "title": "Build broke"}, "timed_out": {"notify": true}}
+// 未覆寫的欄位沿用 theme 的內建樣式，回傳覆寫後的樣式與要通知的 conclusion
+func parseCIConclusions(raw string, theme discord.Theme) (map[string]discord.ConclusionStyle, map[string]bool, error) {
+	styles := make(map[string]discord.ConclusionStyle)
+	notify := make(map[string]bool, len(defaultCINotify))
+	for conclusion, enabled := range defaultCINotify {
+		notify[conclusion] = enabled
+	}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return styles, notify, nil
+	}
+
+	var overrides map[string]ciConclusionOverride
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		return styles, notify, fmt.Errorf("CI_CONCLUSIONS is not a valid JSON object: %w", err)
+	}
+
+	for conclusion, override := range overrides {
+		style := theme.ConclusionStyle(conclusion)
+		if override.Emoji != nil {
+			style.Emoji = *override.Emoji
+		}
+		if override.Title != nil {
+			style.Title = *override.Title
+		}
+		if override.Color != nil {
+			style.Color = *override.Color
+		}
+		styles[conclusion] = style
+
+		if override.Notify != nil {
+			notify[conclusion] = *override.Notify
+		}
+	}
+
+	return styles, notify, nil
+}
@@ -2,40 +2,126 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"dizzycode1112/github-discord-bridge/internal/discord"
+	"dizzycode1112/github-discord-bridge/internal/github"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 )
 
 type Config struct {
-	Port                string
-	Env                 string
-	DiscordBotToken     string
-	DiscordForumChID    string
-	GitHubWebhookSecret string
-	RedisURL            string
+	Port                 string
+	Env                  string
+	NotifierBackend      string // discord（預設）、discord_webhook 或 teams
+	DiscordBotToken      string
+	DiscordForumChID     string
+	DiscordWebhookURL    string // NOTIFIER_BACKEND=discord_webhook 時使用的 forum channel webhook URL
+	DiscordAnnounceChID  string // 發佈 release 公告的 channel（可選，未設定則不公告）
+	GitHubWebhookSecret  string
+	SignatureSchemes     []github.SignatureScheme // 接受的 webhook signature 演算法（WEBHOOK_SIGNATURE_SCHEMES）
+	WebhookMaxBodyBytes  int64                    // webhook request body 上限，超過回 413
+	Replay               github.ReplayPolicy      // 以事件時間做 replay 檢查（REPLAY_WINDOW 為 0 時停用）
+	GitHubToken          string                   // GitHub API token（可選，用於取得 payload 沒有的資訊，例如變更檔案）
+	GitHubAPIURL         string                   // GitHub REST API base URL（Enterprise Server 用）
+	RedisURL             string
+	RedisKeyPrefix       string            // 加在所有 Redis key 前面（共用 Redis 時避免衝突），預設為空
 	GitHubDiscordUserMap map[string]string // GitHub username → Discord user ID
+	DryRun               bool              // 只把要送給 Discord / Teams 的 payload 印到 log，不真的送出；PR mapping 只存在記憶體
+	SkipDiscordVerify    bool              // 跳過啟動時的 Discord credentials 檢查（離線 / dry-run 用）
+	EventsEnabled        map[string]bool   // 各通知類型的開關，未列出的視為開啟（見 events.go）
+	IgnoredAuthors       []AuthorFilter    // 要抑制通知的 PR 作者（見 authors.go）
+	StatusMessage        bool              // 在 thread 中維護一則原地更新的 PR 狀態訊息（STATUS_MESSAGE）
+	PostBackToGitHub     bool              // 建立 thread 後在 PR 下留言附上 thread 連結（POST_BACK_TO_GITHUB，需要 GITHUB_TOKEN）
+
+	// 個別 repo 的 user map（GITHUB_DISCORD_REPO_USER_MAPS，key 為小寫的 repo full name），優先於全域（見 usermap.go）
+	RepoUserMaps map[string]map[string]string
+
+	// 通知的 embed 配色（THEME_COLORS 覆寫內建 theme，見 theme.go）
+	Theme discord.Theme
+
+	// CI 通知：各 conclusion 的樣式覆寫與是否通知（CI_CONCLUSIONS，見 ci.go）
+	CIConclusionStyles  map[string]discord.ConclusionStyle
+	CINotifyConclusions map[string]bool
+	SkipForkCI          bool // 不通知 fork PR 觸發的 workflow run（SKIP_FORK_CI）
+
+	// 同一個 commit 在這段時間內完成的 workflow run 合併成一則通知（CI_COALESCE_WINDOW，0 表示每個 run 各自通知）
+	CICoalesceWindow time.Duration
+
+	// Discord HTTP client 調整（0 表示使用預設值）
+	DiscordTimeout             time.Duration
+	DiscordMaxIdleConnsPerHost int
+	DiscordRateLimit           int           // 每秒最多送出的 Discord API 請求數
+	DiscordAutoArchiveDuration int           // 新 thread 的 auto-archive 時間（分鐘），0 使用 forum channel 的設定
+	ShutdownTimeout            time.Duration // graceful shutdown 等待 in-flight 請求的上限
+
+	// 同時處理 webhook（呼叫 Discord）的上限，0 表示不限制（DISCORD_MAX_CONCURRENCY）
+	// 等待 DiscordConcurrencyWait 仍沒有空位時：DiscordConcurrencyBacklog 為 0 回 503（shed），否則排隊後回 200（enqueue）
+	DiscordMaxConcurrency     int
+	DiscordConcurrencyWait    time.Duration
+	DiscordConcurrencyBacklog int
+
+	// 啟動時 reconcile：補做停機期間漏掉的 archive（需要 GITHUB_TOKEN）
+	ReconcileOnStartup bool
+	ReconcileInterval  time.Duration // 每次 GitHub API 呼叫的間隔
+
+	// Digest 模式：列出的 repo 不即時通知，改為定期發送彙整（DIGEST_REPOS，逗號分隔，支援 *）
+	DigestRepos    []string
+	DigestInterval time.Duration
+
+	// /health 是否檢查 Discord（呼叫 Verify），結果快取 HealthDiscordInterval 避免頻繁 probe 打到 Discord
+	HealthCheckDiscord    bool
+	HealthDiscordInterval time.Duration
+
+	// 信任的 reverse proxy（CIDR 或 IP），只有來自這些位址的 X-Forwarded-For 會被採用（c.ClientIP）
+	TrustedProxies []string
+
+	// webhook 路由的保護：每個 client IP 每秒的請求上限（0 表示停用）與來源 IP allowlist（空的表示不限制）
+	WebhookRateLimit  int
+	WebhookRateBurst  int
+	WebhookAllowedIPs []string
+
+	// Admin API token（未設定時不開放 /admin 路由）
+	AdminToken string
+	// 開放 /debug 路由（查看 PR → thread 對應），同樣需要 AdminToken
+	DebugEndpoints bool
+
+	// Async delivery：webhook 只 publish 到 RabbitMQ，由 consumer 呼叫 Discord
+	AsyncDelivery bool
+	RabbitMQURL   string
+	DeliveryQueue string
+
+	// Microsoft Teams（NOTIFIER_BACKEND=teams 時必填）
+	TeamsServiceURL  string
+	TeamsChannelID   string
+	TeamsAppID       string
+	TeamsAppPassword string
 }
 
 var AppConfig *Config
 
+// Load 讀取 .env 與環境變數並設定 AppConfig，設定有誤時一次列出所有錯誤後結束程式
 func Load() {
 	err := godotenv.Load()
 	if err != nil {
 		log.Println("No .env file found")
 	}
 
-	AppConfig = &Config{
-		Port:                 getEnv("PORT", "3000"),
-		Env:                  getEnv("ENV", "development"),
-		DiscordBotToken:      requireEnv("DISCORD_BOT_TOKEN"),
-		DiscordForumChID:     requireEnv("DISCORD_FORUM_CHANNEL_ID"),
-		GitHubWebhookSecret:  getEnv("GITHUB_WEBHOOK_SECRET", ""),
-		RedisURL:             requireEnv("REDIS_URL"),
-		GitHubDiscordUserMap: parseUserMap(getEnv("GITHUB_DISCORD_USER_MAP", "{}")),
+	cfg, err := Parse()
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
 	}
+	AppConfig = cfg
 
 	if AppConfig.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -44,20 +130,270 @@ func Load() {
 	}
 }
 
-func requireEnv(key string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		log.Fatalf("Env variable %s is required but not set", key)
+// Parse 從環境變數建立 Config，不會結束程式
+// 所有驗證錯誤（缺少必填變數、格式錯誤）會收集起來一起回傳，每行一個
+func Parse() (*Config, error) {
+	var errs []error
+
+	// require 讀取必填變數，未設定時記錄錯誤並回傳空字串
+	require := func(key string) string {
+		value := os.Getenv(key)
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required but not set", key))
+		}
+		return value
+	}
+
+	userMap, err := parseUserMap(getEnv("GITHUB_DISCORD_USER_MAP", "{}"))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	repoUserMaps, err := parseRepoUserMaps(getEnv("GITHUB_DISCORD_REPO_USER_MAPS", ""))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	theme, err := parseThemeColors(getEnv("THEME_COLORS", ""))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	ciStyles, ciNotify, err := parseCIConclusions(getEnv("CI_CONCLUSIONS", ""), theme)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	signatureSchemes, err := github.ParseSignatureSchemes(parseList(getEnv("WEBHOOK_SIGNATURE_SCHEMES", "sha256,sha1")))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("WEBHOOK_SIGNATURE_SCHEMES is invalid: %w", err))
+	}
+
+	cfg := &Config{
+		Port:                 getEnv("PORT", "3000"),
+		Env:                  getEnv("ENV", "development"),
+		NotifierBackend:      getEnv("NOTIFIER_BACKEND", "discord"),
+		DiscordAnnounceChID:  getEnv("DISCORD_ANNOUNCEMENTS_CHANNEL_ID", ""),
+		GitHubWebhookSecret:  getEnv("GITHUB_WEBHOOK_SECRET", ""),
+		SignatureSchemes:     signatureSchemes,
+		WebhookMaxBodyBytes:  getEnvInt64("WEBHOOK_MAX_BODY_BYTES", 5<<20),
+		GitHubToken:          getEnv("GITHUB_TOKEN", ""),
+		GitHubAPIURL:         githubAPIURL(),
+		RedisURL:             require("REDIS_URL"),
+		RedisKeyPrefix:       getEnv("REDIS_KEY_PREFIX", ""),
+		GitHubDiscordUserMap: userMap,
+		RepoUserMaps:         repoUserMaps,
+		DryRun:               getEnvBool("DRY_RUN", false),
+		SkipDiscordVerify:    getEnvBool("SKIP_DISCORD_VERIFY", false),
+		EventsEnabled:        parseEventsEnabled(getEnv("EVENTS_ENABLED", "")),
+		IgnoredAuthors:       parseIgnoredAuthors(getEnv("IGNORED_AUTHORS", "")),
+		StatusMessage:        getEnvBool("STATUS_MESSAGE", false),
+		PostBackToGitHub:     getEnvBool("POST_BACK_TO_GITHUB", false),
+		Theme:                theme,
+		CIConclusionStyles:   ciStyles,
+		CINotifyConclusions:  ciNotify,
+		SkipForkCI:           getEnvBool("SKIP_FORK_CI", false),
+
+		CICoalesceWindow: getEnvDuration("CI_COALESCE_WINDOW", 0),
+
+		DiscordTimeout:             getEnvDuration("DISCORD_HTTP_TIMEOUT", 0),
+		DiscordMaxIdleConnsPerHost: int(getEnvInt64("DISCORD_MAX_IDLE_CONNS_PER_HOST", 2)),
+		DiscordRateLimit:           int(getEnvInt64("DISCORD_RATE_LIMIT", 50)),
+		DiscordAutoArchiveDuration: int(getEnvInt64("DISCORD_AUTO_ARCHIVE_DURATION", 0)),
+		ShutdownTimeout:            getEnvDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
+
+		DiscordMaxConcurrency:  int(getEnvInt64("DISCORD_MAX_CONCURRENCY", 0)),
+		DiscordConcurrencyWait: getEnvDuration("DISCORD_CONCURRENCY_WAIT", 2*time.Second),
+
+		ReconcileOnStartup: getEnvBool("RECONCILE_ON_STARTUP", false),
+		ReconcileInterval:  getEnvDuration("RECONCILE_INTERVAL", time.Second),
+
+		TrustedProxies: parseList(getEnv("TRUSTED_PROXIES", "127.0.0.1,::1")),
+
+		WebhookRateLimit:  int(getEnvInt64("WEBHOOK_RATE_LIMIT", 0)),
+		WebhookRateBurst:  int(getEnvInt64("WEBHOOK_RATE_BURST", 20)),
+		WebhookAllowedIPs: parseList(getEnv("WEBHOOK_ALLOWED_IPS", "")),
+
+		HealthCheckDiscord:    getEnvBool("HEALTH_CHECK_DISCORD", false),
+		HealthDiscordInterval: getEnvDuration("HEALTH_DISCORD_INTERVAL", 5*time.Minute),
+
+		DigestRepos:    parseList(getEnv("DIGEST_REPOS", "")),
+		DigestInterval: getEnvDuration("DIGEST_INTERVAL", 24*time.Hour),
+		AdminToken:     getEnv("ADMIN_TOKEN", ""),
+		DebugEndpoints: getEnvBool("DEBUG_ENDPOINTS", false),
+
+		AsyncDelivery: getEnvBool("ASYNC_DELIVERY", false),
+		DeliveryQueue: getEnv("DELIVERY_QUEUE", "github-discord-bridge.events"),
+
+		Replay: github.ReplayPolicy{
+			Window: getEnvDuration("REPLAY_WINDOW", 0),
+			Header: getEnv("REPLAY_TIMESTAMP_HEADER", ""),
+			Strict: getEnvBool("REPLAY_STRICT", false),
+		},
+	}
+
+	for _, proxy := range cfg.TrustedProxies {
+		if !isIPOrCIDR(proxy) {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES entry %q is not a valid IP or CIDR", proxy))
+		}
+	}
+	for _, entry := range cfg.WebhookAllowedIPs {
+		if !isIPOrCIDR(entry) {
+			errs = append(errs, fmt.Errorf("WEBHOOK_ALLOWED_IPS entry %q is not a valid IP or CIDR", entry))
+		}
+	}
+
+	if cfg.DebugEndpoints && cfg.AdminToken == "" {
+		errs = append(errs, fmt.Errorf("DEBUG_ENDPOINTS requires ADMIN_TOKEN"))
 	}
-	return value
+
+	if cfg.Replay.Strict && !cfg.Replay.Enabled() {
+		log.Printf("Warning: REPLAY_STRICT has no effect without REPLAY_WINDOW")
+	}
+
+	if cfg.RedisURL != "" {
+		if _, err := redis.ParseURL(cfg.RedisURL); err != nil {
+			errs = append(errs, fmt.Errorf("REDIS_URL is invalid: %w", err))
+		}
+	}
+
+	switch overflow := getEnv("DISCORD_CONCURRENCY_OVERFLOW", "shed"); overflow {
+	case "shed":
+	case "enqueue":
+		cfg.DiscordConcurrencyBacklog = int(getEnvInt64("DISCORD_CONCURRENCY_BACKLOG", 100))
+	default:
+		errs = append(errs, fmt.Errorf("DISCORD_CONCURRENCY_OVERFLOW %q is invalid (expected shed or enqueue)", overflow))
+	}
+	if cfg.DiscordMaxConcurrency > 0 && cfg.AsyncDelivery {
+		log.Printf("Warning: DISCORD_MAX_CONCURRENCY has no effect with ASYNC_DELIVERY, the consumer prefetch limits concurrency")
+		cfg.DiscordMaxConcurrency = 0
+	}
+
+	if cfg.PostBackToGitHub && cfg.GitHubToken == "" {
+		log.Printf("Warning: POST_BACK_TO_GITHUB requires GITHUB_TOKEN, PR comments are disabled")
+		cfg.PostBackToGitHub = false
+	}
+	if cfg.PostBackToGitHub && cfg.DryRun {
+		log.Printf("Warning: POST_BACK_TO_GITHUB is disabled in DRY_RUN, dry-run thread IDs have no link")
+		cfg.PostBackToGitHub = false
+	}
+
+	if cfg.DigestInterval <= 0 {
+		log.Printf("Warning: DIGEST_INTERVAL must be positive, using default 24h")
+		cfg.DigestInterval = 24 * time.Hour
+	}
+
+	if cfg.AsyncDelivery {
+		cfg.RabbitMQURL = require("RABBITMQ_URL")
+	}
+
+	// 只檢查選用的後端需要的 credentials
+	switch cfg.NotifierBackend {
+	case "discord":
+		cfg.DiscordBotToken = require("DISCORD_BOT_TOKEN")
+		cfg.DiscordForumChID = require("DISCORD_FORUM_CHANNEL_ID")
+		if cfg.DiscordForumChID != "" && !isSnowflake(cfg.DiscordForumChID) {
+			errs = append(errs, fmt.Errorf("DISCORD_FORUM_CHANNEL_ID %q is not a valid Discord ID", cfg.DiscordForumChID))
+		}
+		if cfg.DiscordAnnounceChID != "" && !isSnowflake(cfg.DiscordAnnounceChID) {
+			errs = append(errs, fmt.Errorf("DISCORD_ANNOUNCEMENTS_CHANNEL_ID %q is not a valid Discord ID", cfg.DiscordAnnounceChID))
+		}
+		if cfg.DiscordAutoArchiveDuration != 0 && !discord.ValidAutoArchiveDuration(cfg.DiscordAutoArchiveDuration) {
+			errs = append(errs, fmt.Errorf("DISCORD_AUTO_ARCHIVE_DURATION %d is invalid (expected one of %v minutes)", cfg.DiscordAutoArchiveDuration, discord.AutoArchiveDurations))
+		}
+	case "discord_webhook":
+		cfg.DiscordWebhookURL = require("DISCORD_WEBHOOK_URL")
+		if cfg.DiscordAnnounceChID != "" {
+			log.Printf("Warning: DISCORD_ANNOUNCEMENTS_CHANNEL_ID is not supported with the discord_webhook backend, release announcements are disabled")
+			cfg.DiscordAnnounceChID = ""
+		}
+		if cfg.PostBackToGitHub {
+			log.Printf("Warning: POST_BACK_TO_GITHUB is only supported with the discord backend, PR comments are disabled")
+			cfg.PostBackToGitHub = false
+		}
+	case "teams":
+		cfg.TeamsServiceURL = require("TEAMS_SERVICE_URL")
+		cfg.TeamsChannelID = require("TEAMS_CHANNEL_ID")
+		cfg.TeamsAppID = require("TEAMS_APP_ID")
+		cfg.TeamsAppPassword = require("TEAMS_APP_PASSWORD")
+		if cfg.PostBackToGitHub {
+			log.Printf("Warning: POST_BACK_TO_GITHUB is only supported with the discord backend, PR comments are disabled")
+			cfg.PostBackToGitHub = false
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported NOTIFIER_BACKEND %q (expected discord, discord_webhook or teams)", cfg.NotifierBackend))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return cfg, nil
+}
+
+// DigestEnabled 回傳 repo 是否使用 digest 模式（DIGEST_REPOS，支援 * 萬用字元）
+func (c *Config) DigestEnabled(repoFullName string) bool {
+	for _, pattern := range c.DigestRepos {
+		if matchGlob(pattern, repoFullName) {
+			return true
+		}
+	}
+	return false
+}
+
+// githubAPIURL 決定 GitHub REST API base URL
+// 優先使用 GITHUB_API_URL；只設定 GITHUB_BASE_URL（Enterprise Server）時推導為 <base>/api/v3
+func githubAPIURL() string {
+	if apiURL := getEnv("GITHUB_API_URL", ""); apiURL != "" {
+		return strings.TrimSuffix(apiURL, "/")
+	}
+	if baseURL := getEnv("GITHUB_BASE_URL", ""); baseURL != "" && baseURL != "https://github.com" {
+		return strings.TrimSuffix(baseURL, "/") + "/api/v3"
+	}
+	return "https://api.github.com"
 }
 
-func parseUserMap(raw string) map[string]string {
+// parseUserMap 解析 GITHUB_DISCORD_USER_MAP（JSON object），value 必須是 Discord user ID
+func parseUserMap(raw string) (map[string]string, error) {
 	m := make(map[string]string)
 	if err := json.Unmarshal([]byte(raw), &m); err != nil {
-		log.Printf("Warning: failed to parse GITHUB_DISCORD_USER_MAP: %v", err)
+		return m, fmt.Errorf("GITHUB_DISCORD_USER_MAP is not a valid JSON object: %w", err)
+	}
+	return m, validateUserMap("GITHUB_DISCORD_USER_MAP", m)
+}
+
+// validateUserMap 檢查 user map 的值都是 Discord user ID，name 用於錯誤訊息
+func validateUserMap(name string, m map[string]string) error {
+	var invalid []string
+	for login, id := range m {
+		if !isSnowflake(id) {
+			invalid = append(invalid, fmt.Sprintf("%s=%q", login, id))
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf("%s has invalid Discord user IDs: %s", name, strings.Join(invalid, ", "))
 	}
-	return m
+	return nil
+}
+
+// isSnowflake 檢查是否為 Discord snowflake ID（17~20 位數字）
+func isSnowflake(id string) bool {
+	if len(id) < 17 || len(id) > 20 {
+		return false
+	}
+	_, err := strconv.ParseUint(id, 10, 64)
+	return err == nil
+}
+
+// parseList 解析逗號分隔清單，忽略空白項目
+func parseList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func getEnv(key, defaultValue string) string {
@@ -66,3 +402,51 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt64(key string, defaultValue int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		log.Printf("Warning: invalid %s=%q, using default %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s=%q, using default %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return b
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Printf("Warning: invalid %s=%q, using default %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// isIPOrCIDR 檢查是否為 IP（例如 10.0.0.1）或 CIDR（例如 10.0.0.0/8）
+func isIPOrCIDR(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+)
+
+// 可用 EVENTS_ENABLED 開關的通知類型
+const (
+	EventOpened          = "opened"
+	EventUpdated         = "updated" // push 新 commit（synchronize）
+	EventMerged          = "merged"
+	EventClosed          = "closed"
+	EventReopened        = "reopened"
+	EventReviewRequested = "review_requested"
+	EventReview          = "review"
+	EventReviewComment   = "review_comment"
+	EventAssigned        = "assigned" // assigned / unassigned
+	EventConflict        = "conflict" // mergeable_state 變成 / 不再是 dirty
+	EventCI              = "ci"       // workflow_run
+	EventRelease         = "release"  // release published
+)
+
+// AllEvents 所有通知類型（預設全部開啟）
+var AllEvents = []string{
+	EventOpened, EventUpdated, EventMerged, EventClosed, EventReopened,
+	EventReviewRequested, EventReview, EventReviewComment, EventAssigned, EventConflict, EventCI, EventRelease,
+}
+
+// EventEnabled 回傳該類型的通知是否開啟
+func (c *Config) EventEnabled(event string) bool {
+	enabled, ok := c.EventsEnabled[event]
+	return !ok || enabled
+}
+
+// parseEventsEnabled 解析 EVENTS_ENABLED
+// 支援兩種格式：
+//  1. 逗號分隔清單 "opened,merged,review"：只開啟列出的類型
+//  2. JSON map {"updated": false, "ci": false}：從全開開始覆寫
+//
+// 空字串表示全部開啟
+func parseEventsEnabled(raw string) map[string]bool {
+	events := make(map[string]bool, len(AllEvents))
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return events
+	}
+
+	if strings.HasPrefix(raw, "{") {
+		var overrides map[string]bool
+		if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+			log.Printf("Warning: failed to parse EVENTS_ENABLED: %v, enabling all events", err)
+			return events
+		}
+		for name, enabled := range overrides {
+			if !isKnownEvent(name) {
+				log.Printf("Warning: unknown event %q in EVENTS_ENABLED", name)
+				continue
+			}
+			events[name] = enabled
+		}
+		return events
+	}
+
+	for _, name := range AllEvents {
+		events[name] = false
+	}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !isKnownEvent(name) {
+			log.Printf("Warning: unknown event %q in EVENTS_ENABLED", name)
+			continue
+		}
+		events[name] = true
+	}
+	return events
+}
+
+func isKnownEvent(name string) bool {
+	for _, e := range AllEvents {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"dizzycode1112/github-discord-bridge/internal/discord"
+)
+
+// parseThemeColors 解析 THEME_COLORS（JSON object，key 為語意名稱、value 為十進位整數顏色）
+// 例如 {"merged": 8476647, "opened": 3066993}，未列出的顏色沿用內建 theme
+func parseThemeColors(raw string) (discord.Theme, error) {
+	theme := discord.DefaultTheme()
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return theme, nil
+	}
+
+	var colors map[string]int
+	if err := json.Unmarshal([]byte(raw), &colors); err != nil {
+		return theme, fmt.Errorf("THEME_COLORS is not a valid JSON object: %w", err)
+	}
+
+	theme, err := theme.WithColors(colors)
+	if err != nil {
+		return theme, fmt.Errorf("THEME_COLORS is invalid: %w", err)
+	}
+	return theme, nil
+}
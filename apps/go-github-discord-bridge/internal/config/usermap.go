@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// parseRepoUserMaps 解析 GITHUB_DISCORD_REPO_USER_MAPS（JSON，key 為 repo full name）
+// 例如 {"other-org/app": {"octocat": "123456789012345678"}}
+// 同一個 GitHub 帳號在不同 repo（不同社群 / org）可能對應不同的 Discord 帳號
+func parseRepoUserMaps(raw string) (map[string]map[string]string, error) {
+	maps := make(map[string]map[string]string)
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return maps, nil
+	}
+
+	var parsed map[string]map[string]string
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return maps, fmt.Errorf("GITHUB_DISCORD_REPO_USER_MAPS is not a valid JSON object of user maps: %w", err)
+	}
+
+	var errs []error
+	for repo, m := range parsed {
+		if !strings.Contains(repo, "/") {
+			errs = append(errs, fmt.Errorf("GITHUB_DISCORD_REPO_USER_MAPS key %q is not a repo full name (owner/repo)", repo))
+			continue
+		}
+		if err := validateUserMap(fmt.Sprintf("GITHUB_DISCORD_REPO_USER_MAPS[%s]", repo), m); err != nil {
+			errs = append(errs, err)
+		}
+		maps[strings.ToLower(repo)] = m
+	}
+
+	return maps, errors.Join(errs...)
+}
+
+// UserMapFor 回傳 repo 要使用的 GitHub → Discord user map：repo 專用的對應優先，其餘沿用全域的 GITHUB_DISCORD_USER_MAP
+func (c *Config) UserMapFor(repoFullName string) map[string]string {
+	override, ok := c.RepoUserMaps[strings.ToLower(repoFullName)]
+	if !ok || len(override) == 0 {
+		return c.GitHubDiscordUserMap
+	}
+
+	merged := make(map[string]string, len(c.GitHubDiscordUserMap)+len(override))
+	for login, id := range c.GitHubDiscordUserMap {
+		merged[login] = id
+	}
+	for login, id := range override {
+		merged[login] = id
+	}
+	return merged
+}
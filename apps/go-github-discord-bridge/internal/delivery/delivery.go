@@ -0,0 +1,83 @@
+package delivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	rabbitmq "weedza.shop/rabbitmq"
+)
+
+// SchemaVersion queue message 格式的版本，格式有不相容變更時遞增
+const SchemaVersion = 1
+
+// Event 送進 queue 的標準化 webhook 事件
+// Payload 保留 GitHub 原始 JSON，consumer 端再解析，避免兩邊 struct 不同步
+type Event struct {
+	Version     int             `json:"version"`
+	DeliveryID  string          `json:"deliveryId"`  // X-GitHub-Delivery
+	GitHubEvent string          `json:"githubEvent"` // X-GitHub-Event
+	Payload     json.RawMessage `json:"payload"`
+	ReceivedAt  time.Time       `json:"receivedAt"`
+}
+
+// Handler consumer 處理單一事件，回傳 error 會依 retry strategy 重試
+type Handler func(event Event) error
+
+const (
+	publisherChannel = "delivery-publisher"
+	consumerChannel  = "delivery-consumer"
+)
+
+// Publisher 把 webhook 事件 publish 到 queue
+type Publisher struct {
+	conn  *rabbitmq.Connection
+	queue string
+}
+
+// NewPublisher 建立 publisher（queue 由 consumer 宣告，publisher 不宣告以免參數不一致）
+func NewPublisher(conn *rabbitmq.Connection, queue string) *Publisher {
+	return &Publisher{
+		conn:  conn,
+		queue: queue,
+	}
+}
+
+// Publish 送出一個事件
+func (p *Publisher) Publish(event Event) error {
+	opts := rabbitmq.DefaultPublishOptions()
+	opts.ChannelID = publisherChannel
+	opts.Headers = amqp.Table{
+		"x-github-event":    event.GitHubEvent,
+		"x-github-delivery": event.DeliveryID,
+	}
+	// 同一個 delivery 被 publish 兩次（例如 GitHub redeliver）時 consumer 只處理一次
+	opts.DeduplicationID = event.DeliveryID
+
+	return rabbitmq.PublishToQueue(p.conn, p.queue, event, &opts)
+}
+
+// StartConsumer 開始消費 queue，Discord 失敗（例如 5xx）會以 exponential backoff 重試，
+// 重試用盡後進入 <queue>.failed
+func StartConsumer(conn *rabbitmq.Connection, queue string, handler Handler) error {
+	opts := &rabbitmq.ConsumeOptions{
+		ConsumerTag:   "github-discord-bridge",
+		RetryStrategy: rabbitmq.NewExponentialBackoff(5, 1000, 2.0),
+		EnableDLQ:     true,
+		ChannelID:     consumerChannel,
+		Deduplication: &rabbitmq.DeduplicationOptions{},
+	}
+
+	return rabbitmq.ConsumeQueue(conn, queue, func(payload []byte, d amqp.Delivery) error {
+		// 格式錯誤的訊息重試也不會成功，直接進 DLQ
+		var event Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return rabbitmq.NewPoisonError(fmt.Errorf("failed to parse event: %w", err))
+		}
+		if event.Version != SchemaVersion {
+			return rabbitmq.NewPoisonError(fmt.Errorf("unsupported event schema version %d", event.Version))
+		}
+		return handler(event)
+	}, opts)
+}
@@ -0,0 +1,92 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"dizzycode1112/github-discord-bridge/internal/discord"
+	"dizzycode1112/github-discord-bridge/internal/notifier"
+	"dizzycode1112/github-discord-bridge/internal/storage"
+	"dizzycode1112/github-discord-bridge/pkg/applogger"
+)
+
+// Scheduler 定期把 digest 模式 repo 累積的事件彙整成一則訊息發送
+type Scheduler struct {
+	store    storage.DigestStore
+	notifier notifier.Notifier
+	interval time.Duration
+	mu       sync.Mutex // 避免排程與 admin endpoint 同時 flush
+}
+
+// NewScheduler 建立 digest scheduler
+func NewScheduler(store storage.DigestStore, n notifier.Notifier, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		notifier: n,
+		interval: interval,
+	}
+}
+
+// Run 每隔 interval flush 一次，ctx 取消時返回（未發送的事件留在 store，下次啟動後再發）
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Flush(ctx); err != nil {
+				applogger.Log.Error("Scheduled digest flush failed", "error", err)
+			}
+		}
+	}
+}
+
+// Flush 立即發送所有待發送的 digest，每個 repo 建立一個 thread，回傳發送的 repo 數
+// 發送失敗的 repo 會把事件放回 store，下次再試
+func (s *Scheduler) Flush(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log := applogger.Log
+
+	// 部分 digest 取出失敗時，已取出的照常發送（它們已從 store 刪除，不發就會遺失）
+	digests, firstErr := s.store.TakeDigests(ctx)
+	if firstErr != nil {
+		log.Error("Failed to take some digests", "error", firstErr)
+	}
+
+	sent := 0
+	for repo, entries := range digests {
+		title := fmt.Sprintf("📋 %s digest — %s", repo, time.Now().Format("2006-01-02"))
+		if _, err := s.notifier.CreateThread(ctx, title, discord.FormatDigest(repo, toDigestItems(entries))); err != nil {
+			log.Error("Failed to post digest, re-queueing entries", "repo", repo, "entries", len(entries), "error", err)
+			for _, e := range entries {
+				if err := s.store.AppendDigest(ctx, e); err != nil {
+					log.Error("Failed to re-queue digest entry", "repo", repo, "pr", e.Number, "error", err)
+				}
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		sent++
+	}
+
+	log.Info("Digest flushed", "repos", sent)
+	return sent, firstErr
+}
+
+// toDigestItems 把 store 中的事件轉成 formatter 使用的 DigestItem
+func toDigestItems(entries []storage.DigestEntry) []discord.DigestItem {
+	items := make([]discord.DigestItem, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, discord.DigestItem{Kind: e.Kind, Number: e.Number, Title: e.Title, URL: e.URL, Author: e.Author})
+	}
+	return items
+}
@@ -0,0 +1,74 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"dizzycode1112/github-discord-bridge/internal/discord"
+	"dizzycode1112/github-discord-bridge/internal/storage"
+	"dizzycode1112/github-discord-bridge/pkg/applogger"
+)
+
+// partialStore TakeDigests 回傳部分結果與錯誤的 DigestStore，模擬某個 key 取出失敗
+type partialStore struct {
+	taken    map[string][]storage.DigestEntry
+	takeErr  error
+	appended []storage.DigestEntry
+}
+
+func (s *partialStore) AppendDigest(ctx context.Context, entry storage.DigestEntry) error {
+	s.appended = append(s.appended, entry)
+	return nil
+}
+
+func (s *partialStore) TakeDigests(ctx context.Context) (map[string][]storage.DigestEntry, error) {
+	return s.taken, s.takeErr
+}
+
+// recordingNotifier 記錄 CreateThread 的標題
+type recordingNotifier struct {
+	mu     sync.Mutex
+	titles []string
+}
+
+func (n *recordingNotifier) CreateThread(ctx context.Context, title string, message discord.ThreadMessage, tagIDs ...string) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.titles = append(n.titles, title)
+	return "thread-1", nil
+}
+
+func (n *recordingNotifier) PostMessage(ctx context.Context, threadID string, message discord.ThreadMessage) error {
+	return nil
+}
+
+func (n *recordingNotifier) ArchiveThread(ctx context.Context, threadID string) error {
+	return nil
+}
+
+func TestFlushSendsDigestsTakenBeforeAnError(t *testing.T) {
+	applogger.Init("test")
+
+	takeErr := errors.New("failed to take digest digest:acme/other:2026-01-02")
+	store := &partialStore{
+		taken: map[string][]storage.DigestEntry{
+			"acme/bridge": {{Repo: "acme/bridge", Kind: "opened", Number: 7, Title: "Add feature"}},
+		},
+		takeErr: takeErr,
+	}
+	n := &recordingNotifier{}
+
+	sent, err := NewScheduler(store, n, 0).Flush(context.Background())
+
+	if !errors.Is(err, takeErr) {
+		t.Fatalf("Flush err = %v, want the TakeDigests error", err)
+	}
+	if sent != 1 || len(n.titles) != 1 {
+		t.Fatalf("sent = %d, threads = %v; want the already-taken digest to be posted", sent, n.titles)
+	}
+	if len(store.appended) != 0 {
+		t.Fatalf("re-queued %d entries that were posted", len(store.appended))
+	}
+}
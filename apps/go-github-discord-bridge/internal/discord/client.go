@@ -2,11 +2,16 @@ package discord
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"sync"
 	"time"
+
+	"dizzycode1112/github-discord-bridge/pkg/applogger"
 )
 
 const (
@@ -17,29 +22,240 @@ type Client struct {
 	token          string
 	forumChannelID string
 	httpClient     *http.Client
+	limiter        *rateLimiter // 所有請求共用（Discord global rate limit 以 bot 為單位）
+	autoArchive    int          // 新 thread 的 auto_archive_duration（分鐘），0 使用 channel 設定
+	dryRun         bool
+
+	guildMu sync.Mutex
+	guildID string // forum channel 所在的 server，第一次組 thread 連結時查詢後快取
+}
+
+// ClientOptions 調整 HTTP client 的連線池與 timeout
+// 零值欄位使用預設值（與 http.DefaultTransport 相同，整體 timeout 10 秒）
+type ClientOptions struct {
+	Timeout               time.Duration // 單一請求的總 timeout
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration // 0 表示不限制
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	IdleConnTimeout       time.Duration
+	RateLimit             int // 每秒請求數上限（所有 goroutine 共用）
+	AutoArchiveDuration   int // 新 thread 閒置多久後自動 archive（分鐘，須為 AutoArchiveDurations 之一），0 使用 channel 設定
+}
+
+// DefaultClientOptions 回傳預設的 client 設定
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		Timeout:             10 * time.Second,
+		DialTimeout:         30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: http.DefaultMaxIdleConnsPerHost,
+		IdleConnTimeout:     90 * time.Second,
+		RateLimit:           DefaultRateLimit,
+	}
 }
 
-// NewClient 建立 Discord API client
-func NewClient(token, forumChannelID string) *Client {
+// NewClient 建立 Discord API client，opts 為 nil 時使用預設值
+func NewClient(token, forumChannelID string, opts *ClientOptions) *Client {
+	o := DefaultClientOptions()
+	if opts != nil {
+		if opts.Timeout > 0 {
+			o.Timeout = opts.Timeout
+		}
+		if opts.DialTimeout > 0 {
+			o.DialTimeout = opts.DialTimeout
+		}
+		if opts.TLSHandshakeTimeout > 0 {
+			o.TLSHandshakeTimeout = opts.TLSHandshakeTimeout
+		}
+		if opts.ResponseHeaderTimeout > 0 {
+			o.ResponseHeaderTimeout = opts.ResponseHeaderTimeout
+		}
+		if opts.MaxIdleConns > 0 {
+			o.MaxIdleConns = opts.MaxIdleConns
+		}
+		if opts.MaxIdleConnsPerHost > 0 {
+			o.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+		}
+		if opts.IdleConnTimeout > 0 {
+			o.IdleConnTimeout = opts.IdleConnTimeout
+		}
+		if opts.RateLimit > 0 {
+			o.RateLimit = opts.RateLimit
+		}
+		o.AutoArchiveDuration = opts.AutoArchiveDuration
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   o.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = o.TLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = o.ResponseHeaderTimeout
+	transport.MaxIdleConns = o.MaxIdleConns
+	transport.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = o.IdleConnTimeout
+
 	return &Client{
 		token:          token,
 		forumChannelID: forumChannelID,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   o.Timeout,
+			Transport: transport,
 		},
+		limiter:     newRateLimiter(o.RateLimit),
+		autoArchive: o.AutoArchiveDuration,
+	}
+}
+
+// AutoArchiveDurations Discord 接受的 thread auto_archive_duration（分鐘）：1 小時、1 天、3 天、1 週
+var AutoArchiveDurations = []int{60, 1440, 4320, 10080}
+
+// ValidAutoArchiveDuration 檢查 minutes 是否為 Discord 接受的值
+func ValidAutoArchiveDuration(minutes int) bool {
+	for _, d := range AutoArchiveDurations {
+		if d == minutes {
+			return true
+		}
 	}
+	return false
+}
+
+// EnableDryRun 開啟 dry-run 模式：所有會改動 Discord 的呼叫只把 payload 印到 log，不真的送出
+// 用於新部署時用真實 webhook 流量驗證 formatter 與 mention 邏輯
+func (c *Client) EnableDryRun() {
+	c.dryRun = true
+}
+
+// logDryRun 印出 dry-run 模式下原本要送出的 payload
+func logDryRun(action string, target string, payload any) {
+	jsonData, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		applogger.Log.Error("[dry-run] Failed to marshal payload", "action", action, "error", err)
+		return
+	}
+	applogger.Log.Info("[dry-run] Discord request skipped", "action", action, "target", target, "payload", string(jsonData))
 }
 
 // CreateThreadRequest 建立 thread 的請求結構
 type CreateThreadRequest struct {
-	Name        string        `json:"name"`                    // Thread 標題
-	Message     ThreadMessage `json:"message"`                 // 第一則訊息
-	AppliedTags []string      `json:"applied_tags,omitempty"`  // Forum tags (可選)
+	Name        string        `json:"name"`                   // Thread 標題
+	Message     ThreadMessage `json:"message"`                // 第一則訊息
+	AppliedTags []string      `json:"applied_tags,omitempty"` // Forum tags (可選)
+
+	AutoArchiveDuration int `json:"auto_archive_duration,omitempty"` // 閒置多久後自動 archive（分鐘），不送時使用 channel 設定
 }
 
 type ThreadMessage struct {
-	Content string  `json:"content,omitempty"` // 純文字內容
-	Embeds  []Embed `json:"embeds,omitempty"`  // Rich embed
+	Content    string      `json:"content,omitempty"`    // 純文字內容
+	Embeds     []Embed     `json:"embeds,omitempty"`     // Rich embed
+	Components []ActionRow `json:"components,omitempty"` // 訊息下方的按鈕列
+
+	// 限制這則訊息實際會 ping 的對象，nil 時送出前補上 NoMentions()
+	AllowedMentions *AllowedMentions `json:"allowed_mentions,omitempty"`
+
+	// 只有透過 webhook 發送時有效（WebhookClient），覆寫顯示的名稱與頭像
+	Username  string `json:"username,omitempty"`
+	AvatarURL string `json:"avatar_url,omitempty"`
+
+	// 以回覆的形式發送（只有 bot 後端有效，webhook 會忽略），用 ReplyTo 設定
+	MessageReference *MessageReference `json:"message_reference,omitempty"`
+}
+
+// MessageReference 被回覆的訊息
+type MessageReference struct {
+	MessageID string `json:"message_id"`
+	// false 時被回覆的訊息已被刪除，Discord 會改為一般訊息發送，而不是回 400
+	FailIfNotExists bool `json:"fail_if_not_exists"`
+}
+
+// ReplyTo 回傳回覆 messageID 的訊息複本
+// forum thread 的第一則訊息（PR Opened）ID 與 thread ID 相同，回覆它時傳入 thread ID 即可
+// 被回覆的訊息已被刪除時仍會以一般訊息發送
+func (m ThreadMessage) ReplyTo(messageID string) ThreadMessage {
+	m.MessageReference = &MessageReference{MessageID: messageID}
+	return m
+}
+
+// AllowedMentions 控制 content 中哪些 mention 會真的通知
+// Parse 為空陣列時不解析任何 @everyone / @here / role / user mention，只通知 Users 與 Roles 列出的 ID
+// PR 標題、內文等外部文字可能帶 @everyone，因此一律明確列出要 ping 的 ID
+type AllowedMentions struct {
+	Parse       []string `json:"parse"`
+	Users       []string `json:"users,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	RepliedUser bool     `json:"replied_user,omitempty"`
+}
+
+// NoMentions 不通知任何人
+func NoMentions() *AllowedMentions {
+	return &AllowedMentions{Parse: []string{}}
+}
+
+// MentionUsers 只通知指定的 Discord user ID
+func MentionUsers(userIDs ...string) *AllowedMentions {
+	return &AllowedMentions{Parse: []string{}, Users: userIDs}
+}
+
+// withAllowedMentions 沒有設定 AllowedMentions 的訊息一律不通知任何人
+func withAllowedMentions(message ThreadMessage) ThreadMessage {
+	if message.AllowedMentions == nil {
+		message.AllowedMentions = NoMentions()
+	}
+	return message
+}
+
+// prepareMessage 送出前的共同處理：補上 AllowedMentions，並裁到 Discord 的長度限制內
+func prepareMessage(message ThreadMessage) ThreadMessage {
+	return withAllowedMentions(message).Truncate()
+}
+
+// Discord message component 的 type 與 button style
+const (
+	ComponentTypeActionRow = 1
+	ComponentTypeButton    = 2
+	ButtonStyleLink        = 5 // 連結按鈕，點擊直接開網址，不需要處理 interaction
+)
+
+// ActionRow 一列 component（type 1），最多放 5 個 button
+type ActionRow struct {
+	Type       int      `json:"type"`
+	Components []Button `json:"components"`
+}
+
+// Button message component（type 2）
+type Button struct {
+	Type  int    `json:"type"`
+	Style int    `json:"style"`
+	Label string `json:"label"`
+	URL   string `json:"url,omitempty"` // 只有 link button 使用
+}
+
+// LinkButtonRow 建立一列連結按鈕，略過 URL 為空的按鈕；全部為空時回傳 nil
+func LinkButtonRow(buttons ...Button) []ActionRow {
+	var row []Button
+	for _, b := range buttons {
+		if b.URL != "" {
+			row = append(row, b)
+		}
+	}
+	if len(row) == 0 {
+		return nil
+	}
+	return []ActionRow{{Type: ComponentTypeActionRow, Components: row}}
+}
+
+// LinkButton 建立連結按鈕
+func LinkButton(label, url string) Button {
+	return Button{
+		Type:  ComponentTypeButton,
+		Style: ButtonStyleLink,
+		Label: label,
+		URL:   url,
+	}
 }
 
 // Embed Discord 的 rich embed 結構
@@ -51,6 +267,7 @@ type Embed struct {
 	Fields      []EmbedField `json:"fields,omitempty"`
 	Timestamp   string       `json:"timestamp,omitempty"` // ISO 8601 format
 	Footer      *EmbedFooter `json:"footer,omitempty"`
+	Author      *EmbedAuthor `json:"author,omitempty"`
 }
 
 type EmbedField struct {
@@ -64,6 +281,13 @@ type EmbedFooter struct {
 	IconURL string `json:"icon_url,omitempty"`
 }
 
+// EmbedAuthor 顯示在 embed 最上方的作者（頭像 + 名稱）
+type EmbedAuthor struct {
+	Name    string `json:"name"`
+	URL     string `json:"url,omitempty"`
+	IconURL string `json:"icon_url,omitempty"`
+}
+
 // CreateThreadResponse Discord API 的回應
 type CreateThreadResponse struct {
 	ID   string `json:"id"`   // Thread ID
@@ -81,32 +305,129 @@ type ForumChannelResponse struct {
 	AvailableTags []ForumTag `json:"available_tags"`
 }
 
-// GetOrCreateRepoTag 取得或建立 repo 對應的 forum tag，回傳 tag ID
-// 如果 forum 已有同名 tag 就直接用，沒有就建立新的
-func (c *Client) GetOrCreateRepoTag(repoName string) (string, error) {
-	// 取得 forum channel 資訊
-	url := fmt.Sprintf("%s/channels/%s", DiscordAPIBase, c.forumChannelID)
+// ChannelTypeGuildForum Discord forum channel 的 type
+const ChannelTypeGuildForum = 15
+
+// Verify 啟動時檢查 bot token 與 forum channel 設定是否正確
+// 1. GET /users/@me 確認 token 有效
+// 2. GET /channels/{forumChannelID} 確認 channel 存在且為 forum
+func (c *Client) Verify(ctx context.Context) error {
+	var me struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/users/@me", DiscordAPIBase), &me); err != nil {
+		return fmt.Errorf("invalid DISCORD_BOT_TOKEN: %w", err)
+	}
+
+	var channel struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Type int    `json:"type"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/channels/%s", DiscordAPIBase, c.forumChannelID), &channel); err != nil {
+		return fmt.Errorf("cannot access DISCORD_FORUM_CHANNEL_ID %s (bot %s): %w", c.forumChannelID, me.Username, err)
+	}
+
+	if channel.Type != ChannelTypeGuildForum {
+		return fmt.Errorf("channel %s (#%s) is not a forum channel (type %d)", c.forumChannelID, channel.Name, channel.Type)
+	}
+
+	return nil
+}
+
+// ThreadURL 回傳 thread 在 Discord 的連結（https://discord.com/channels/{guild}/{thread}）
+// guild ID 第一次呼叫時從 forum channel 查詢，之後重用
+func (c *Client) ThreadURL(ctx context.Context, threadID string) (string, error) {
+	c.guildMu.Lock()
+	defer c.guildMu.Unlock()
+
+	if c.guildID == "" {
+		var channel struct {
+			GuildID string `json:"guild_id"`
+		}
+		if err := c.getJSON(ctx, fmt.Sprintf("%s/channels/%s", DiscordAPIBase, c.forumChannelID), &channel); err != nil {
+			return "", fmt.Errorf("failed to get guild of forum channel %s: %w", c.forumChannelID, err)
+		}
+		if channel.GuildID == "" {
+			return "", fmt.Errorf("forum channel %s has no guild_id", c.forumChannelID)
+		}
+		c.guildID = channel.GuildID
+	}
+
+	return fmt.Sprintf("https://discord.com/channels/%s/%s", c.guildID, threadID), nil
+}
+
+// getJSON 發送 GET 請求並將 JSON 回應解析到 out
+func (c *Client) getJSON(ctx context.Context, url string, out any) error {
+	return c.do(ctx, "GET", url, nil, out)
+}
+
+// do 發送 Discord API 請求：payload 不為 nil 時以 JSON 送出，out 不為 nil 時解析回應
+// ctx 取消時中止排隊等待 rate limit 與進行中的請求；非 2xx 一律回傳 *DiscordAPIError
+func (c *Client) do(ctx context.Context, method, url string, payload any, out any) error {
+	var reqBody io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewBuffer(jsonData)
+	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bot "+c.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if err := c.limiter.wait(ctx); err != nil {
+		return fmt.Errorf("rate limiter: %w", err)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to get channel: %w", err)
+		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// global rate limit：暫停所有 goroutine 的請求，本次呼叫仍回傳 429 由呼叫端決定是否重試
+	if retryAfter, global := globalRetryAfter(resp); global {
+		applogger.Log.Warn("Discord global rate limit hit, pausing requests", "retryAfter", retryAfter.String())
+		c.limiter.pause(retryAfter)
+	}
+
 	body, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("discord API error (status %d): %s", resp.StatusCode, string(body))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp.StatusCode, body)
 	}
 
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetOrCreateRepoTag 取得或建立 repo 對應的 forum tag，回傳 tag ID
+// 如果 forum 已有同名 tag 就直接用，沒有就建立新的
+func (c *Client) GetOrCreateRepoTag(ctx context.Context, repoName string) (string, error) {
+	if c.dryRun {
+		logDryRun("GetOrCreateRepoTag", c.forumChannelID, map[string]string{"name": repoName})
+		return "dry-run-tag-" + repoName, nil
+	}
+
+	// 取得 forum channel 資訊
+	url := fmt.Sprintf("%s/channels/%s", DiscordAPIBase, c.forumChannelID)
+
 	var channel ForumChannelResponse
-	if err := json.Unmarshal(body, &channel); err != nil {
-		return "", fmt.Errorf("failed to parse channel: %w", err)
+	if err := c.getJSON(ctx, url, &channel); err != nil {
+		return "", fmt.Errorf("failed to get channel: %w", err)
 	}
 
 	// 找已存在的 tag
@@ -122,33 +443,11 @@ func (c *Client) GetOrCreateRepoTag(repoName string) (string, error) {
 	type PatchBody struct {
 		AvailableTags []ForumTag `json:"available_tags"`
 	}
-	patchData, err := json.Marshal(PatchBody{AvailableTags: newTags})
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal patch: %w", err)
-	}
-
-	patchReq, err := http.NewRequest("PATCH", url, bytes.NewBuffer(patchData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create patch request: %w", err)
-	}
-	patchReq.Header.Set("Authorization", "Bot "+c.token)
-	patchReq.Header.Set("Content-Type", "application/json")
-
-	patchResp, err := c.httpClient.Do(patchReq)
-	if err != nil {
-		return "", fmt.Errorf("failed to patch channel: %w", err)
-	}
-	defer patchResp.Body.Close()
-
-	patchBody, _ := io.ReadAll(patchResp.Body)
-	if patchResp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("discord API error on patch (status %d): %s", patchResp.StatusCode, string(patchBody))
-	}
 
-	// 重新解析拿到新 tag 的 ID
+	// PATCH 回應包含新 tag 的 ID
 	var updated ForumChannelResponse
-	if err := json.Unmarshal(patchBody, &updated); err != nil {
-		return "", fmt.Errorf("failed to parse updated channel: %w", err)
+	if err := c.do(ctx, "PATCH", url, PatchBody{AvailableTags: newTags}, &updated); err != nil {
+		return "", fmt.Errorf("failed to patch channel: %w", err)
 	}
 
 	for _, tag := range updated.AvailableTags {
@@ -161,77 +460,91 @@ func (c *Client) GetOrCreateRepoTag(repoName string) (string, error) {
 }
 
 // CreateThread 在 forum channel 建立新的 thread
-func (c *Client) CreateThread(title string, message ThreadMessage, tagIDs ...string) (string, error) {
+func (c *Client) CreateThread(ctx context.Context, title string, message ThreadMessage, tagIDs ...string) (string, error) {
 	url := fmt.Sprintf("%s/channels/%s/threads", DiscordAPIBase, c.forumChannelID)
 
 	reqBody := CreateThreadRequest{
 		Name:        title,
-		Message:     message,
+		Message:     prepareMessage(message),
 		AppliedTags: tagIDs,
+
+		AutoArchiveDuration: c.autoArchive,
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+	if c.dryRun {
+		logDryRun("CreateThread", c.forumChannelID, reqBody)
+		return fmt.Sprintf("dry-run-%d", time.Now().UnixNano()), nil
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+	var result CreateThreadResponse
+	if err := c.do(ctx, "POST", url, reqBody, &result); err != nil {
+		return "", err
 	}
 
-	req.Header.Set("Authorization", "Bot "+c.token)
-	req.Header.Set("Content-Type", "application/json")
+	return result.ID, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+// PostMessage 在已存在的 thread 中發送訊息
+func (c *Client) PostMessage(ctx context.Context, threadID string, message ThreadMessage) error {
+	_, err := c.CreateMessage(ctx, threadID, message)
+	return err
+}
 
-	body, _ := io.ReadAll(resp.Body)
+// MessageResponse Discord 回傳的訊息（只取需要的欄位）
+type MessageResponse struct {
+	ID string `json:"id"`
+}
 
-	if resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("discord API error (status %d): %s", resp.StatusCode, string(body))
-	}
+// CreateMessage 在 thread 中發送訊息並回傳 message ID（之後可用 EditMessage 修改）
+func (c *Client) CreateMessage(ctx context.Context, threadID string, message ThreadMessage) (string, error) {
+	url := fmt.Sprintf("%s/channels/%s/messages", DiscordAPIBase, threadID)
+	message = prepareMessage(message)
 
-	var result CreateThreadResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+	if c.dryRun {
+		logDryRun("PostMessage", threadID, message)
+		return fmt.Sprintf("dry-run-%d", time.Now().UnixNano()), nil
 	}
 
+	var result MessageResponse
+	if err := c.do(ctx, "POST", url, message, &result); err != nil {
+		return "", err
+	}
 	return result.ID, nil
 }
 
-// PostMessage 在已存在的 thread 中發送訊息
-func (c *Client) PostMessage(threadID string, message ThreadMessage) error {
-	url := fmt.Sprintf("%s/channels/%s/messages", DiscordAPIBase, threadID)
+// EditMessage 修改 thread 中已發送的訊息（整則取代 content / embeds / components）
+// 訊息已被刪除時回傳 404（可用 IsNotFound 判斷）
+func (c *Client) EditMessage(ctx context.Context, threadID, messageID string, message ThreadMessage) error {
+	url := fmt.Sprintf("%s/channels/%s/messages/%s", DiscordAPIBase, threadID, messageID)
+	message = prepareMessage(message)
 
-	jsonData, err := json.Marshal(message)
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+	if c.dryRun {
+		logDryRun("EditMessage", threadID+"/"+messageID, message)
+		return nil
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
+	return c.do(ctx, "PATCH", url, message, nil)
+}
 
-	req.Header.Set("Authorization", "Bot "+c.token)
-	req.Header.Set("Content-Type", "application/json")
+// RenameThreadRequest 修改 thread 名稱的請求
+type RenameThreadRequest struct {
+	Name string `json:"name"`
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+// RenameThread 修改 thread 標題（title 應為 FormatThreadTitle 的結果，已限制 100 字元）
+func (c *Client) RenameThread(ctx context.Context, threadID, title string) error {
+	url := fmt.Sprintf("%s/channels/%s", DiscordAPIBase, threadID)
+
+	reqBody := RenameThreadRequest{
+		Name: title,
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("discord API error (status %d): %s", resp.StatusCode, string(body))
+	if c.dryRun {
+		logDryRun("RenameThread", threadID, reqBody)
+		return nil
 	}
 
-	return nil
+	return c.do(ctx, "PATCH", url, reqBody, nil)
 }
 
 // ArchiveThreadRequest archive thread 的請求
@@ -240,36 +553,17 @@ type ArchiveThreadRequest struct {
 }
 
 // ArchiveThread 關閉並 archive 一個 thread
-func (c *Client) ArchiveThread(threadID string) error {
+func (c *Client) ArchiveThread(ctx context.Context, threadID string) error {
 	url := fmt.Sprintf("%s/channels/%s", DiscordAPIBase, threadID)
 
 	reqBody := ArchiveThreadRequest{
 		Archived: true,
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bot "+c.token)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+	if c.dryRun {
+		logDryRun("ArchiveThread", threadID, reqBody)
+		return nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("discord API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	return nil
+	return c.do(ctx, "PATCH", url, reqBody, nil)
 }
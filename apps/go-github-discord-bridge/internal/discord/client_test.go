@@ -0,0 +1,20 @@
+package discord
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReplyToDoesNotFailWhenReferenceIsGone(t *testing.T) {
+	// 被回覆的 PR Opened 訊息被刪除時，Discord 應改為一般訊息發送，而不是回 400
+	data, err := json.Marshal(ThreadMessage{Content: "hello"}.ReplyTo("thread-1"))
+	if err != nil {
+		t.Fatalf("failed to marshal message: %v", err)
+	}
+
+	want := `"message_reference":{"message_id":"thread-1","fail_if_not_exists":false}`
+	if !strings.Contains(string(data), want) {
+		t.Fatalf("payload = %s, want it to contain %s", data, want)
+	}
+}
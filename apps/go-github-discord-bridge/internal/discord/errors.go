@@ -0,0 +1,62 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DiscordAPIError Discord API 回傳非 2xx 時的錯誤
+// 可用 errors.As 取出，或用 IsNotFound / IsForbidden / IsRateLimited 判斷
+type DiscordAPIError struct {
+	StatusCode int    // HTTP status
+	Code       int    // Discord JSON error code（例如 10003 Unknown Channel），無法解析時為 0
+	Message    string // Discord 回傳的 message，無法解析時為原始 body
+}
+
+func (e *DiscordAPIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("discord API error (status %d, code %d): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("discord API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// newAPIError 從 response body 解析 Discord 的 {"code": ..., "message": ...}
+func newAPIError(statusCode int, body []byte) *DiscordAPIError {
+	apiErr := &DiscordAPIError{StatusCode: statusCode}
+
+	var parsed struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Message != "" {
+		apiErr.Code = parsed.Code
+		apiErr.Message = parsed.Message
+	} else {
+		apiErr.Message = string(body)
+	}
+
+	return apiErr
+}
+
+// hasStatus 判斷 err 鏈中是否有指定 status 的 DiscordAPIError
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *DiscordAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// IsNotFound 資源不存在（例如 thread 已被手動刪除）
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsForbidden bot 缺少權限
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsRateLimited 被 Discord rate limit
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
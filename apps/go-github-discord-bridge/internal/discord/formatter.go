@@ -3,10 +3,11 @@ package discord
 import (
 	"dizzycode1112/github-discord-bridge/internal/github"
 	"fmt"
+	"strings"
 	"time"
 )
 
-// Discord 顏色常數（整數格式）
+// Discord 顏色常數（整數格式），內建 theme 的配色（見 theme.go）
 const (
 	ColorGreen  = 0x57F287 // PR opened, approved
 	ColorYellow = 0xFEE75C // PR review requested
@@ -15,8 +16,21 @@ const (
 	ColorGray   = 0x99AAB5 // General info
 )
 
+// clock 取得目前時間，只用於 payload 沒有帶事件時間的通知（可在測試中替換）
+var clock = time.Now
+
+// timestamp 格式化 embed 時間：優先使用 payload 帶的事件時間，延遲處理或重送時才不會顯示成處理當下的時間
+// t 為零值時使用 clock()
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		t = clock()
+	}
+	return t.Format(time.RFC3339)
+}
+
 // FormatPROpened 格式化「PR 開啟」的訊息
-func FormatPROpened(pr *github.PullRequest) ThreadMessage {
+// files 為變更檔案路徑（可為 nil，例如未設定 GitHub token 時），有值才顯示 Files 欄位
+func FormatPROpened(pr *github.PullRequest, files []string) ThreadMessage {
 	description := pr.Body
 	if len(description) > 500 {
 		description = description[:497] + "..."
@@ -29,7 +43,7 @@ func FormatPROpened(pr *github.PullRequest) ThreadMessage {
 		Title:       fmt.Sprintf("Pull Request #%d Opened", pr.Number),
 		Description: description,
 		URL:         pr.HTMLURL,
-		Color:       ColorGreen,
+		Color:       theme.Opened,
 		Fields: []EmbedField{
 			{
 				Name:   "Author",
@@ -48,15 +62,45 @@ func FormatPROpened(pr *github.PullRequest) ThreadMessage {
 			},
 		},
 		Timestamp: pr.CreatedAt.Format(time.RFC3339),
+		Author:    embedAuthor(pr.User),
 		Footer: &EmbedFooter{
 			Text:    "GitHub",
 			IconURL: "https://github.githubassets.com/images/modules/logos_page/GitHub-Mark.png",
 		},
 	}
 
+	if len(files) > 0 {
+		embed.Fields = append(embed.Fields, EmbedField{
+			Name:  "Files",
+			Value: formatFileList(files, pr.ChangedFiles),
+		})
+	}
+
 	return ThreadMessage{
 		Embeds: []Embed{embed},
+		Components: LinkButtonRow(
+			LinkButton("View PR", pr.HTMLURL),
+			LinkButton("View Diff", pr.DiffURL),
+		),
+	}
+}
+
+// formatFileList 將檔案路徑列成清單，總數超過列出數量時加上 "+N more"
+func formatFileList(files []string, total int) string {
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString(fmt.Sprintf("`%s`\n", f))
+	}
+	if more := total - len(files); more > 0 {
+		b.WriteString(fmt.Sprintf("+%d more", more))
+	}
+
+	// Discord embed field value 上限 1024 字元
+	value := strings.TrimSuffix(b.String(), "\n")
+	if len(value) > 1024 {
+		value = value[:1021] + "..."
 	}
+	return value
 }
 
 // FormatPRReview 格式化「PR Review」的訊息
@@ -68,16 +112,16 @@ func FormatPRReview(review *github.Review, prNumber int, prURL string, prAuthorL
 	switch review.State {
 	case "approved":
 		emoji = "✅"
-		color = ColorGreen
+		color = theme.Opened
 	case "changes_requested":
 		emoji = "🔴"
-		color = ColorRed
+		color = theme.Closed
 	case "commented":
 		emoji = "💬"
-		color = ColorGray
+		color = theme.Info
 	default:
 		emoji = "📝"
-		color = ColorGray
+		color = theme.Info
 	}
 
 	title := fmt.Sprintf("%s Review by @%s", emoji, review.User.Login)
@@ -96,44 +140,180 @@ func FormatPRReview(review *github.Review, prNumber int, prURL string, prAuthorL
 		Description: description,
 		URL:         review.HTMLURL,
 		Color:       color,
-		Timestamp:   review.SubmittedAt.Format(time.RFC3339),
+		Timestamp:   timestamp(review.SubmittedAt),
+		Author:      embedAuthor(review.User),
 	}
 
 	// 只有 approved / changes_requested 才 mention PR 作者（commented 不打擾）
 	var content string
+	mentions := NoMentions()
 	if review.State == "approved" || review.State == "changes_requested" {
 		if discordID, ok := userMap[prAuthorLogin]; ok {
 			content = fmt.Sprintf("<@%s>", discordID)
+			mentions = MentionUsers(discordID)
 		} else {
 			content = fmt.Sprintf("@%s", prAuthorLogin)
 		}
 	}
 
 	return ThreadMessage{
-		Content: content,
-		Embeds:  []Embed{embed},
+		Content:         content,
+		Embeds:          []Embed{embed},
+		AllowedMentions: mentions,
 	}
 }
 
+const (
+	// maxDiffHunkLines 行內留言最多顯示 diff 的最後幾行（被留言的那一行在最後）
+	maxDiffHunkLines = 12
+	// maxDiffHunkChars diff 區塊的字元上限，加上留言內容仍遠低於 embed description 的 4096 字元
+	maxDiffHunkChars = 1500
+)
+
+// FormatReviewComment 格式化 review 行內留言，附上被留言的 diff 片段
+func FormatReviewComment(comment *github.ReviewComment, prNumber int, prURL string) ThreadMessage {
+	body := comment.Body
+	if len(body) > 800 {
+		body = body[:797] + "..."
+	}
+
+	description := body
+	if hunk := formatDiffHunk(comment.DiffHunk); hunk != "" {
+		description = "```diff\n" + hunk + "\n```\n" + body
+	}
+
+	embed := Embed{
+		Title:       fmt.Sprintf("💬 @%s commented on `%s`", comment.User.Login, comment.Path),
+		Description: description,
+		URL:         comment.HTMLURL,
+		Color:       theme.Info,
+		Timestamp:   timestamp(comment.CreatedAt),
+		Author:      embedAuthor(comment.User),
+		Footer: &EmbedFooter{
+			Text: fmt.Sprintf("PR #%d", prNumber),
+		},
+	}
+	if embed.URL == "" {
+		embed.URL = prURL
+	}
+
+	return ThreadMessage{
+		Embeds: []Embed{embed},
+	}
+}
+
+// formatDiffHunk 只保留 diff 的最後 maxDiffHunkLines 行（含 @@ header 時略過），並限制字元數
+// 內容中的 ``` 會被拆開，避免提前結束 code block
+func formatDiffHunk(hunk string) string {
+	lines := strings.Split(strings.TrimRight(hunk, "\n"), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "@@") {
+		lines = lines[1:]
+	}
+	if len(lines) > maxDiffHunkLines {
+		lines = lines[len(lines)-maxDiffHunkLines:]
+	}
+
+	result := strings.ReplaceAll(strings.Join(lines, "\n"), "```", "`\u200b``")
+	// 超過上限時從前面截掉，保留被留言的最後一行（以字元計算，避免切斷多位元組字元）
+	if runes := []rune(result); len(runes) > maxDiffHunkChars {
+		result = "…" + string(runes[len(runes)-maxDiffHunkChars+1:])
+	}
+	return result
+}
+
 // FormatReviewRequested 格式化「Review Requested」的訊息
 func FormatReviewRequested(reviewer *github.User, requestedBy string, prNumber int, prURL string, userMap map[string]string) ThreadMessage {
 	// Discord mention 只在 content 才有效，embed title/description 不支援
 	var content string
+	mentions := NoMentions()
 	if discordID, ok := userMap[reviewer.Login]; ok {
 		content = fmt.Sprintf("<@%s>", discordID)
+		mentions = MentionUsers(discordID)
 	}
 
 	embed := Embed{
 		Title:       fmt.Sprintf("🔔 Review requested from @%s", reviewer.Login),
 		Description: fmt.Sprintf("@%s requested a review on PR #%d", requestedBy, prNumber),
 		URL:         prURL,
-		Color:       ColorYellow,
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Color:       theme.Review,
+		Timestamp:   clock().Format(time.RFC3339),
+	}
+
+	return ThreadMessage{
+		Content:         content,
+		Embeds:          []Embed{embed},
+		AllowedMentions: mentions,
+	}
+}
+
+// FormatAssigned 格式化「指派 PR」的訊息，assignee 有對應的 Discord 帳號時 mention
+func FormatAssigned(assignee *github.User, assignedBy string, prNumber int, prURL string, userMap map[string]string) ThreadMessage {
+	var content string
+	mentions := NoMentions()
+	if discordID, ok := userMap[assignee.Login]; ok {
+		content = fmt.Sprintf("<@%s>", discordID)
+		mentions = MentionUsers(discordID)
+	}
+
+	embed := Embed{
+		Title:       fmt.Sprintf("👤 @%s assigned", assignee.Login),
+		Description: fmt.Sprintf("@%s assigned @%s to PR #%d", assignedBy, assignee.Login, prNumber),
+		URL:         prURL,
+		Color:       theme.Info,
+		Timestamp:   clock().Format(time.RFC3339),
+	}
+
+	return ThreadMessage{
+		Content:         content,
+		Embeds:          []Embed{embed},
+		AllowedMentions: mentions,
+	}
+}
+
+// FormatUnassigned 格式化「取消指派」的訊息（不 mention）
+func FormatUnassigned(assignee *github.User, unassignedBy string, prNumber int, prURL string) ThreadMessage {
+	embed := Embed{
+		Title:       fmt.Sprintf("👤 @%s unassigned", assignee.Login),
+		Description: fmt.Sprintf("@%s removed @%s from PR #%d", unassignedBy, assignee.Login, prNumber),
+		URL:         prURL,
+		Color:       theme.Info,
+		Timestamp:   clock().Format(time.RFC3339),
 	}
 
 	return ThreadMessage{
-		Content: content,
-		Embeds:  []Embed{embed},
+		Embeds: []Embed{embed},
+	}
+}
+
+// FormatReReviewRequested 格式化「修改完成，請重新 review」的訊息
+// reviewer 上一輪要求修改，作者 push 後重新請求 review 時使用，用來 close the loop
+func FormatReReviewRequested(reviewer *github.User, requestedBy string, round int, prNumber int, prURL string, userMap map[string]string) ThreadMessage {
+	var content string
+	mentions := NoMentions()
+	if discordID, ok := userMap[reviewer.Login]; ok {
+		content = fmt.Sprintf("<@%s>", discordID)
+		mentions = MentionUsers(discordID)
+	}
+
+	embed := Embed{
+		Title:       fmt.Sprintf("🔁 Changes pushed, please re-review @%s", reviewer.Login),
+		Description: fmt.Sprintf("@%s addressed the requested changes on PR #%d and asked for another review", requestedBy, prNumber),
+		URL:         prURL,
+		Color:       theme.Review,
+		Fields: []EmbedField{
+			{
+				Name:   "Review round",
+				Value:  fmt.Sprintf("%d", round),
+				Inline: true,
+			},
+		},
+		Timestamp: clock().Format(time.RFC3339),
+	}
+
+	return ThreadMessage{
+		Content:         content,
+		Embeds:          []Embed{embed},
+		AllowedMentions: mentions,
 	}
 }
 
@@ -143,7 +323,7 @@ func FormatPRMerged(pr *github.PullRequest, mergedBy string) ThreadMessage {
 		Title:       fmt.Sprintf("🎉 PR #%d Merged", pr.Number),
 		Description: fmt.Sprintf("**%s** has been merged into `%s`", pr.Title, pr.Base.Ref),
 		URL:         pr.HTMLURL,
-		Color:       ColorPurple,
+		Color:       theme.Merged,
 		Fields: []EmbedField{
 			{
 				Name:   "Merged by",
@@ -156,7 +336,7 @@ func FormatPRMerged(pr *github.PullRequest, mergedBy string) ThreadMessage {
 				Inline: true,
 			},
 		},
-		Timestamp: time.Now().Format(time.RFC3339),
+		Timestamp: timestamp(pr.MergedAt),
 		Footer: &EmbedFooter{
 			Text: "Thread will be archived soon",
 		},
@@ -173,7 +353,7 @@ func FormatPRClosed(pr *github.PullRequest, closedBy string) ThreadMessage {
 		Title:       fmt.Sprintf("❌ PR #%d Closed", pr.Number),
 		Description: fmt.Sprintf("**%s** was closed without merging", pr.Title),
 		URL:         pr.HTMLURL,
-		Color:       ColorRed,
+		Color:       theme.Closed,
 		Fields: []EmbedField{
 			{
 				Name:   "Closed by",
@@ -181,7 +361,7 @@ func FormatPRClosed(pr *github.PullRequest, closedBy string) ThreadMessage {
 				Inline: true,
 			},
 		},
-		Timestamp: time.Now().Format(time.RFC3339),
+		Timestamp: timestamp(pr.ClosedAt),
 		Footer: &EmbedFooter{
 			Text: "Thread will be archived soon",
 		},
@@ -198,7 +378,7 @@ func FormatPRUpdated(pr *github.PullRequest) ThreadMessage {
 		Title:       "🔄 PR Updated",
 		Description: fmt.Sprintf("New commits pushed to `%s`", pr.Head.Ref),
 		URL:         pr.HTMLURL,
-		Color:       ColorYellow,
+		Color:       theme.Review,
 		Fields: []EmbedField{
 			{
 				Name:   "Changes",
@@ -214,6 +394,45 @@ func FormatPRUpdated(pr *github.PullRequest) ThreadMessage {
 	}
 }
 
+// FormatMergeConflict 格式化「出現 merge conflict」的訊息，作者有對應的 Discord 帳號時 mention 提醒 rebase
+func FormatMergeConflict(pr *github.PullRequest, userMap map[string]string) ThreadMessage {
+	var content string
+	mentions := NoMentions()
+	if discordID, ok := userMap[pr.User.Login]; ok {
+		content = fmt.Sprintf("<@%s>", discordID)
+		mentions = MentionUsers(discordID)
+	}
+
+	embed := Embed{
+		Title:       "⚠️ Merge conflicts detected",
+		Description: fmt.Sprintf("`%s` conflicts with `%s`, please rebase or merge the base branch", pr.Head.Ref, pr.Base.Ref),
+		URL:         pr.HTMLURL,
+		Color:       theme.Closed,
+		Timestamp:   clock().Format(time.RFC3339),
+	}
+
+	return ThreadMessage{
+		Content:         content,
+		Embeds:          []Embed{embed},
+		AllowedMentions: mentions,
+	}
+}
+
+// FormatMergeConflictResolved 格式化「merge conflict 已解決」的訊息（不 mention）
+func FormatMergeConflictResolved(pr *github.PullRequest) ThreadMessage {
+	embed := Embed{
+		Title:       "✅ Merge conflicts resolved",
+		Description: fmt.Sprintf("`%s` can be merged into `%s` again", pr.Head.Ref, pr.Base.Ref),
+		URL:         pr.HTMLURL,
+		Color:       theme.Opened,
+		Timestamp:   clock().Format(time.RFC3339),
+	}
+
+	return ThreadMessage{
+		Embeds: []Embed{embed},
+	}
+}
+
 // formatReviewState 轉換 review state 成易讀的文字
 func formatReviewState(state string) string {
 	switch state {
@@ -229,32 +448,12 @@ func formatReviewState(state string) string {
 }
 
 // FormatWorkflowRunResult 格式化 CI/CD 結果通知
-func FormatWorkflowRunResult(wr *github.WorkflowRun) ThreadMessage {
-	var emoji string
-	var title string
-	var color int
-
-	switch wr.Conclusion {
-	case "success":
-		emoji = "✅"
-		title = fmt.Sprintf("%s CI Passed", emoji)
-		color = ColorGreen
-	case "failure":
-		emoji = "❌"
-		title = fmt.Sprintf("%s CI Failed", emoji)
-		color = ColorRed
-	case "timed_out":
-		emoji = "⏰"
-		title = fmt.Sprintf("%s CI Timed Out", emoji)
-		color = ColorRed
-	case "cancelled":
-		emoji = "🚫"
-		title = fmt.Sprintf("%s CI Cancelled", emoji)
-		color = ColorGray
-	default:
-		emoji = "❓"
-		title = fmt.Sprintf("%s CI: %s", emoji, wr.Conclusion)
-		color = ColorGray
+// style 為該 conclusion 的樣式（見 DefaultConclusionStyle）
+// failedJobs 為失敗的 job（沒有 GitHub token 時為 nil，不顯示該欄位）
+func FormatWorkflowRunResult(wr *github.WorkflowRun, style ConclusionStyle, failedJobs []github.WorkflowJob) ThreadMessage {
+	title := style.Title
+	if style.Emoji != "" {
+		title = style.Emoji + " " + title
 	}
 
 	commitShort := wr.HeadSHA
@@ -268,8 +467,157 @@ func FormatWorkflowRunResult(wr *github.WorkflowRun) ThreadMessage {
 		Title:       title,
 		Description: description,
 		URL:         wr.HTMLURL,
+		Color:       style.Color,
+		Timestamp:   timestamp(wr.UpdatedAt),
+	}
+
+	if len(failedJobs) > 0 {
+		lines := make([]string, 0, len(failedJobs))
+		for _, job := range failedJobs {
+			line := fmt.Sprintf("[%s](%s)", job.Name, job.HTMLURL)
+			if steps := job.FailedSteps(); len(steps) > 0 {
+				line += " — " + strings.Join(steps, ", ")
+			}
+			lines = append(lines, line)
+		}
+		embed.Fields = append(embed.Fields, EmbedField{
+			Name:  "Failed Jobs",
+			Value: joinLines(lines, 1024),
+		})
+	}
+
+	// 連結到 workflow run 頁面，失敗時可以在頁面上 re-run
+	return ThreadMessage{
+		Embeds:     []Embed{embed},
+		Components: LinkButtonRow(LinkButton("View Run", wr.HTMLURL)),
+	}
+}
+
+// CIRunResult FormatCISummary 顯示的一個 workflow run 結果
+type CIRunResult struct {
+	Workflow   string
+	Conclusion string
+	URL        string
+	HeadSHA    string
+	At         time.Time
+}
+
+// FormatCISummary 把同一個 commit 的多個 workflow run 結果合併成一則訊息（CI_COALESCE_WINDOW）
+// 任一 workflow 不是 success 時整體以該 conclusion 的樣式顯示；followUp 為時間窗之後才到的結果
+func FormatCISummary(results []CIRunResult, style func(conclusion string) ConclusionStyle, followUp bool) ThreadMessage {
+	overall := "success"
+	var commitShort string
+	var latest time.Time
+	lines := make([]string, 0, len(results))
+	for _, r := range results {
+		s := style(r.Conclusion)
+		lines = append(lines, fmt.Sprintf("%s [%s](%s) — %s", s.Emoji, r.Workflow, r.URL, r.Conclusion))
+		if r.Conclusion != "success" && overall == "success" {
+			overall = r.Conclusion
+		}
+		if r.At.After(latest) {
+			latest = r.At
+		}
+		commitShort = r.HeadSHA
+	}
+	if len(commitShort) > 7 {
+		commitShort = commitShort[:7]
+	}
+
+	overallStyle := style(overall)
+	title := overallStyle.Title
+	if overallStyle.Emoji != "" {
+		title = overallStyle.Emoji + " " + title
+	}
+	if len(results) > 1 {
+		title += fmt.Sprintf(" (%d workflows)", len(results))
+	}
+	if followUp {
+		title += " — follow-up"
+	}
+
+	embed := Embed{
+		Title:       title,
+		Description: fmt.Sprintf("Commit `%s`\n\n%s", commitShort, joinLines(lines, 4000)),
+		Color:       overallStyle.Color,
+		Timestamp:   timestamp(latest),
+	}
+
+	return ThreadMessage{
+		Embeds: []Embed{embed},
+	}
+}
+
+// ConclusionStyle CI 結果訊息的 emoji、標題與顏色
+type ConclusionStyle struct {
+	Emoji string
+	Title string
+	Color int
+}
+
+// defaultConclusionStyles 內建的 workflow run conclusion 樣式，顏色由 Theme.ConclusionStyle 填入
+var defaultConclusionStyles = map[string]ConclusionStyle{
+	"success":   {Emoji: "✅", Title: "CI Passed"},
+	"failure":   {Emoji: "❌", Title: "CI Failed"},
+	"timed_out": {Emoji: "⏰", Title: "CI Timed Out"},
+	"cancelled": {Emoji: "🚫", Title: "CI Cancelled"},
+}
+
+// DefaultConclusionStyle 回傳 conclusion 的內建樣式（使用目前的 theme），未知的 conclusion 顯示原始值
+func DefaultConclusionStyle(conclusion string) ConclusionStyle {
+	return theme.ConclusionStyle(conclusion)
+}
+
+// FormatRelease 格式化「Release 發佈」的公告訊息
+// 不屬於任何 PR thread，直接發在公告 channel
+func FormatRelease(release *github.Release, repoFullName string) ThreadMessage {
+	name := release.Name
+	if name == "" {
+		name = release.TagName
+	}
+
+	emoji := "🚀"
+	color := theme.Merged
+	if release.Prerelease {
+		emoji = "🧪"
+		color = theme.Review
+	}
+
+	title := fmt.Sprintf("%s %s %s", emoji, repoFullName, name)
+	if release.Prerelease {
+		title += " (pre-release)"
+	}
+
+	description := release.Body
+	if len(description) > 1000 {
+		description = description[:997] + "..."
+	}
+	if description == "" {
+		description = "*No release notes provided*"
+	}
+
+	embed := Embed{
+		Title:       title,
+		Description: description,
+		URL:         release.HTMLURL,
 		Color:       color,
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Fields: []EmbedField{
+			{
+				Name:   "Tag",
+				Value:  fmt.Sprintf("`%s`", release.TagName),
+				Inline: true,
+			},
+			{
+				Name:   "Published by",
+				Value:  fmt.Sprintf("[@%s](%s)", release.Author.Login, release.Author.HTMLURL),
+				Inline: true,
+			},
+		},
+		Timestamp: release.PublishedAt.Format(time.RFC3339),
+		Footer: &EmbedFooter{
+			Text:    "GitHub",
+			IconURL: "https://github.githubassets.com/images/modules/logos_page/GitHub-Mark.png",
+		},
 	}
 
 	return ThreadMessage{
@@ -277,6 +625,76 @@ func FormatWorkflowRunResult(wr *github.WorkflowRun) ThreadMessage {
 	}
 }
 
+// DigestItem FormatDigest 列出的一筆 PR 事件
+type DigestItem struct {
+	Kind   string // opened, merged, closed
+	Number int
+	Title  string
+	URL    string
+	Author string
+}
+
+// FormatDigest 格式化 digest 模式的彙整訊息：依 opened/merged/closed 分組列出 PR 連結
+func FormatDigest(repoFullName string, entries []DigestItem) ThreadMessage {
+	groups := map[string][]string{}
+	for _, e := range entries {
+		groups[e.Kind] = append(groups[e.Kind], fmt.Sprintf("[#%d %s](%s) by @%s", e.Number, e.Title, e.URL, e.Author))
+	}
+
+	embed := Embed{
+		Title:       fmt.Sprintf("📋 %s digest", repoFullName),
+		Description: fmt.Sprintf("%d opened · %d merged · %d closed", len(groups["opened"]), len(groups["merged"]), len(groups["closed"])),
+		Color:       theme.Info,
+		Timestamp:   clock().Format(time.RFC3339),
+	}
+
+	for _, kind := range []struct{ key, name string }{
+		{"opened", "🆕 Opened"},
+		{"merged", "🎉 Merged"},
+		{"closed", "🚫 Closed"},
+	} {
+		lines := groups[kind.key]
+		if len(lines) == 0 {
+			continue
+		}
+		embed.Fields = append(embed.Fields, EmbedField{
+			Name:  fmt.Sprintf("%s (%d)", kind.name, len(lines)),
+			Value: joinLines(lines, 1024),
+		})
+	}
+
+	return ThreadMessage{
+		Embeds: []Embed{embed},
+	}
+}
+
+// joinLines 以換行串接，超過 limit 字元（embed field 上限）時改以 "+N more" 結尾
+func joinLines(lines []string, limit int) string {
+	var b strings.Builder
+	for i, line := range lines {
+		more := fmt.Sprintf("+%d more", len(lines)-i)
+		if b.Len()+len(line)+1+len(more) > limit {
+			b.WriteString(more)
+			return b.String()
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// embedAuthor 用 GitHub 使用者的 login、個人頁面與頭像建立 embed author，沒有 login 時回傳 nil
+func embedAuthor(user github.User) *EmbedAuthor {
+	if user.Login == "" {
+		return nil
+	}
+	return &EmbedAuthor{
+		Name:    user.Login,
+		URL:     user.HTMLURL,
+		IconURL: user.AvatarURL,
+	}
+}
+
 // FormatThreadTitle 格式化 thread 標題（限制 100 字元）
 // repoFullName 格式為 "owner/repo"，只取 repo 名稱作為前綴
 func FormatThreadTitle(prNumber int, prTitle string, repoFullName string) string {
@@ -292,9 +710,9 @@ func FormatThreadTitle(prNumber int, prTitle string, repoFullName string) string
 
 	title := fmt.Sprintf("[%s] PR #%d: %s", repoName, prNumber, prTitle)
 
-	// Discord forum thread title 限制 100 字元
-	if len(title) > 100 {
-		return title[:97] + "..."
+	// Discord forum thread title 限制 100 字元（以字元計算，避免切斷多位元組字元）
+	if runes := []rune(title); len(runes) > 100 {
+		return string(runes[:97]) + "..."
 	}
 
 	return title
@@ -0,0 +1,45 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatDigestGroupsByKind(t *testing.T) {
+	message := FormatDigest("acme/bridge", []DigestItem{
+		{Kind: "opened", Number: 1, Title: "Add feature", URL: "https://github.com/acme/bridge/pull/1", Author: "alice"},
+		{Kind: "merged", Number: 2, Title: "Fix bug", URL: "https://github.com/acme/bridge/pull/2", Author: "bob"},
+		{Kind: "opened", Number: 3, Title: "Docs", URL: "https://github.com/acme/bridge/pull/3", Author: "carol"},
+	})
+
+	embed := message.Embeds[0]
+	if embed.Description != "2 opened · 1 merged · 0 closed" {
+		t.Fatalf("description = %q", embed.Description)
+	}
+	if len(embed.Fields) != 2 || embed.Fields[0].Name != "🆕 Opened (2)" || embed.Fields[1].Name != "🎉 Merged (1)" {
+		t.Fatalf("fields = %+v, want Opened (2) and Merged (1)", embed.Fields)
+	}
+	if !strings.Contains(embed.Fields[1].Value, "[#2 Fix bug](https://github.com/acme/bridge/pull/2) by @bob") {
+		t.Fatalf("merged field = %q", embed.Fields[1].Value)
+	}
+}
+
+func TestFormatCISummaryUsesWorstConclusion(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	message := FormatCISummary([]CIRunResult{
+		{Workflow: "Lint", Conclusion: "success", URL: "https://example.com/1", HeadSHA: "0123456789abcdef", At: at},
+		{Workflow: "Test", Conclusion: "failure", URL: "https://example.com/2", HeadSHA: "0123456789abcdef", At: at.Add(time.Minute)},
+	}, DefaultConclusionStyle, false)
+
+	embed := message.Embeds[0]
+	if embed.Title != "❌ CI Failed (2 workflows)" {
+		t.Fatalf("title = %q", embed.Title)
+	}
+	if !strings.HasPrefix(embed.Description, "Commit `0123456`") {
+		t.Fatalf("description = %q", embed.Description)
+	}
+	if embed.Timestamp != at.Add(time.Minute).Format(time.RFC3339) {
+		t.Fatalf("timestamp = %q, want latest run", embed.Timestamp)
+	}
+}
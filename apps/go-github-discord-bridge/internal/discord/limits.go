@@ -0,0 +1,123 @@
+package discord
+
+import "unicode/utf8"
+
+// Discord 對訊息與 embed 的長度限制（以字元計算），超過時整個請求回 400
+const (
+	MaxContentLength     = 2000
+	MaxEmbeds            = 10
+	MaxEmbedTitle        = 256
+	MaxEmbedDescription  = 4096
+	MaxEmbedFields       = 25
+	MaxEmbedFieldName    = 256
+	MaxEmbedFieldValue   = 1024
+	MaxEmbedFooterText   = 2048
+	MaxEmbedAuthorName   = 256
+	MaxEmbedsTotalLength = 6000 // 同一則訊息所有 embed 的 title、description、fields、footer、author 加總
+)
+
+// truncatedNote 被截斷的 description 結尾加上的說明
+const truncatedNote = "\n…truncated"
+
+// Truncate 把訊息裁到 Discord 的限制內，避免整則訊息被 400 拒絕
+// 個別欄位超過上限時截斷；fields 超過 25 個時只保留前 25 個
+// 所有 embed 加總仍超過 6000 字元時，從最後一個 embed 開始縮短 description、再移除 fields
+// description 被截斷時結尾加上「…truncated」
+func (m ThreadMessage) Truncate() ThreadMessage {
+	m.Content = truncateText(m.Content, MaxContentLength, "…")
+
+	if len(m.Embeds) > MaxEmbeds {
+		m.Embeds = m.Embeds[:MaxEmbeds]
+	}
+	// 複製一份，不改到呼叫端的 slice
+	embeds := make([]Embed, len(m.Embeds))
+	for i, e := range m.Embeds {
+		embeds[i] = e.truncate()
+	}
+
+	over := -MaxEmbedsTotalLength
+	for _, e := range embeds {
+		over += e.length()
+	}
+	for i := len(embeds) - 1; i >= 0 && over > 0; i-- {
+		e := &embeds[i]
+
+		if descLen := utf8.RuneCountInString(e.Description); descLen > 0 {
+			keep := descLen - over
+			if keep < 0 {
+				keep = 0
+			}
+			e.Description = truncateText(e.Description, keep, truncatedNote)
+			over -= descLen - utf8.RuneCountInString(e.Description)
+		}
+
+		for over > 0 && len(e.Fields) > 0 {
+			last := e.Fields[len(e.Fields)-1]
+			e.Fields = e.Fields[:len(e.Fields)-1]
+			over -= utf8.RuneCountInString(last.Name) + utf8.RuneCountInString(last.Value)
+		}
+	}
+	m.Embeds = embeds
+
+	return m
+}
+
+// truncate 把 embed 的各欄位裁到個別上限內
+func (e Embed) truncate() Embed {
+	e.Title = truncateText(e.Title, MaxEmbedTitle, "…")
+	e.Description = truncateText(e.Description, MaxEmbedDescription, truncatedNote)
+
+	if len(e.Fields) > MaxEmbedFields {
+		e.Fields = e.Fields[:MaxEmbedFields]
+	}
+	fields := make([]EmbedField, len(e.Fields))
+	for i, f := range e.Fields {
+		f.Name = truncateText(f.Name, MaxEmbedFieldName, "…")
+		f.Value = truncateText(f.Value, MaxEmbedFieldValue, "…")
+		fields[i] = f
+	}
+	if e.Fields != nil {
+		e.Fields = fields
+	}
+
+	if e.Footer != nil {
+		footer := *e.Footer
+		footer.Text = truncateText(footer.Text, MaxEmbedFooterText, "…")
+		e.Footer = &footer
+	}
+	if e.Author != nil {
+		author := *e.Author
+		author.Name = truncateText(author.Name, MaxEmbedAuthorName, "…")
+		e.Author = &author
+	}
+	return e
+}
+
+// length 回傳 embed 計入 6000 字元上限的長度
+func (e Embed) length() int {
+	n := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	for _, f := range e.Fields {
+		n += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	if e.Footer != nil {
+		n += utf8.RuneCountInString(e.Footer.Text)
+	}
+	if e.Author != nil {
+		n += utf8.RuneCountInString(e.Author.Name)
+	}
+	return n
+}
+
+// truncateText 超過 limit 個字元時截斷並加上 suffix（總長度不超過 limit）
+func truncateText(s string, limit int, suffix string) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+
+	runes := []rune(s)
+	suffixRunes := []rune(suffix)
+	if limit <= len(suffixRunes) {
+		return string(runes[:limit])
+	}
+	return string(runes[:limit-len(suffixRunes)]) + suffix
+}
@@ -0,0 +1,98 @@
+package discord
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultRateLimit Discord 的 global rate limit 為每秒 50 個請求（每個 bot）
+const DefaultRateLimit = 50
+
+// rateLimiter 所有請求共用的 token bucket，多個 goroutine 同時呼叫也安全
+// 收到 global 429 時暫停所有請求直到 Retry-After 到期
+type rateLimiter struct {
+	mu          sync.Mutex
+	rate        float64 // 每秒補充的 token 數
+	burst       float64 // bucket 容量
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newRateLimiter(perSecond int) *rateLimiter {
+	return &rateLimiter{
+		rate:   float64(perSecond),
+		burst:  float64(perSecond),
+		tokens: float64(perSecond),
+		last:   time.Now(),
+	}
+}
+
+// wait 取得一個 token，沒有 token 或處於 global 暫停時等待；ctx 取消時回傳錯誤
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		delay := l.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve 嘗試取得 token，成功回傳 0，否則回傳需要等待的時間
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// pause 暫停所有請求 d 的時間（global rate limit）
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.tokens = 0
+}
+
+// globalRetryAfter 從 429 回應判斷是否為 global rate limit，回傳要暫停的時間
+// Discord 以 X-RateLimit-Global header 標示，Retry-After 為秒數（可能有小數）
+func globalRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("X-RateLimit-Global") != "true" {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64)
+	if err != nil || seconds <= 0 {
+		seconds = 1
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
@@ -0,0 +1,90 @@
+package discord
+
+import (
+	"dizzycode1112/github-discord-bridge/internal/github"
+	"fmt"
+	"time"
+)
+
+// PRStatus thread 狀態訊息顯示的 PR 狀態
+type PRStatus string
+
+const (
+	StatusOpen             PRStatus = "open"
+	StatusDraft            PRStatus = "draft"
+	StatusReviewRequested  PRStatus = "review_requested"
+	StatusApproved         PRStatus = "approved"
+	StatusChangesRequested PRStatus = "changes_requested"
+	StatusMerged           PRStatus = "merged"
+	StatusClosed           PRStatus = "closed"
+)
+
+// statusLabels 各狀態的標籤
+var statusLabels = map[PRStatus]string{
+	StatusOpen:             "🟢 Open",
+	StatusDraft:            "📝 Draft",
+	StatusReviewRequested:  "👀 Review Requested",
+	StatusApproved:         "✅ Approved",
+	StatusChangesRequested: "🔴 Changes Requested",
+	StatusMerged:           "🎉 Merged",
+	StatusClosed:           "❌ Closed",
+}
+
+// statusColor 回傳狀態在 t 中對應的顏色
+func (t Theme) statusColor(status PRStatus) int {
+	switch status {
+	case StatusOpen, StatusApproved:
+		return t.Opened
+	case StatusReviewRequested:
+		return t.Review
+	case StatusChangesRequested, StatusClosed:
+		return t.Closed
+	case StatusMerged:
+		return t.Merged
+	default:
+		return t.Info
+	}
+}
+
+// OpenStatus 依 draft 與否回傳 PR 開啟中的狀態
+func OpenStatus(pr *github.PullRequest) PRStatus {
+	if pr.Draft {
+		return StatusDraft
+	}
+	return StatusOpen
+}
+
+// FormatPRStatus 格式化 thread 的狀態訊息，PR 狀態改變時以 EditMessage 原地更新
+func FormatPRStatus(pr *github.PullRequest, status PRStatus) ThreadMessage {
+	label, ok := statusLabels[status]
+	if !ok {
+		label = string(status)
+	}
+
+	embed := Embed{
+		Title:       fmt.Sprintf("PR #%d · %s", pr.Number, label),
+		Description: fmt.Sprintf("**%s**", pr.Title),
+		URL:         pr.HTMLURL,
+		Color:       theme.statusColor(status),
+		Fields: []EmbedField{
+			{
+				Name:   "Author",
+				Value:  fmt.Sprintf("[@%s](%s)", pr.User.Login, pr.User.HTMLURL),
+				Inline: true,
+			},
+			{
+				Name:   "Branch",
+				Value:  fmt.Sprintf("`%s` → `%s`", pr.Head.Ref, pr.Base.Ref),
+				Inline: true,
+			},
+		},
+		Timestamp: clock().Format(time.RFC3339),
+		Footer: &EmbedFooter{
+			Text: "Status · updated in place",
+		},
+	}
+
+	return ThreadMessage{
+		Embeds: []Embed{embed},
+	}
+}
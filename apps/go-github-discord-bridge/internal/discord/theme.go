@@ -0,0 +1,100 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Theme 各類通知的 embed 顏色，formatter 一律從目前的 theme 取色（THEME_COLORS 可覆寫，見 config）
+type Theme struct {
+	Opened int // PR opened、approved、衝突解除
+	Review int // review requested、有新 commit、重新開啟、pre-release
+	Merged int // PR merged、release
+	Closed int // PR closed、changes requested、合併衝突
+	CIPass int // CI success
+	CIFail int // CI failure、timed out
+	Info   int // 一般資訊（留言、指派、digest、CI cancelled）
+}
+
+// ThemeColorNames THEME_COLORS 可用的 key，依序對應 Theme 的欄位
+var ThemeColorNames = []string{"opened", "review", "merged", "closed", "ci-pass", "ci-fail", "info"}
+
+// DefaultTheme 回傳內建的配色
+func DefaultTheme() Theme {
+	return Theme{
+		Opened: ColorGreen,
+		Review: ColorYellow,
+		Merged: ColorPurple,
+		Closed: ColorRed,
+		CIPass: ColorGreen,
+		CIFail: ColorRed,
+		Info:   ColorGray,
+	}
+}
+
+// theme formatter 目前使用的配色，啟動時以 SetTheme 設定
+var theme = DefaultTheme()
+
+// SetTheme 設定 formatter 使用的配色，需在開始處理 webhook 之前呼叫
+func SetTheme(t Theme) {
+	theme = t
+}
+
+// CurrentTheme 回傳 formatter 目前使用的配色
+func CurrentTheme() Theme {
+	return theme
+}
+
+// WithColors 回傳套用覆寫後的 theme，key 為 ThemeColorNames 之一，顏色為 0 ~ 0xFFFFFF 的整數
+func (t Theme) WithColors(colors map[string]int) (Theme, error) {
+	for name, color := range colors {
+		field := t.field(name)
+		if field == nil {
+			return t, fmt.Errorf("unknown theme color %q (expected one of %s)", name, strings.Join(ThemeColorNames, ", "))
+		}
+		if color < 0 || color > 0xFFFFFF {
+			return t, fmt.Errorf("theme color %q must be between 0 and 16777215 (0xFFFFFF), got %d", name, color)
+		}
+		*field = color
+	}
+	return t, nil
+}
+
+// field 回傳 key 對應的欄位，未知的 key 回傳 nil
+func (t *Theme) field(name string) *int {
+	switch name {
+	case "opened":
+		return &t.Opened
+	case "review":
+		return &t.Review
+	case "merged":
+		return &t.Merged
+	case "closed":
+		return &t.Closed
+	case "ci-pass":
+		return &t.CIPass
+	case "ci-fail":
+		return &t.CIFail
+	case "info":
+		return &t.Info
+	}
+	return nil
+}
+
+// ConclusionStyle 回傳 conclusion 的內建樣式（顏色取自 t），未知的 conclusion 顯示原始值
+func (t Theme) ConclusionStyle(conclusion string) ConclusionStyle {
+	style, ok := defaultConclusionStyles[conclusion]
+	if !ok {
+		style = ConclusionStyle{Emoji: "❓", Title: "CI: " + conclusion}
+	}
+
+	switch conclusion {
+	case "success":
+		style.Color = t.CIPass
+	case "failure", "timed_out":
+		style.Color = t.CIFail
+	default:
+		style.Color = t.Info
+	}
+	return style
+}
@@ -0,0 +1,163 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// DefaultWebhookUsername / DefaultWebhookAvatarURL 訊息沒有指定時顯示的名稱與頭像
+	DefaultWebhookUsername  = "GitHub"
+	DefaultWebhookAvatarURL = "https://github.githubassets.com/images/modules/logos_page/GitHub-Mark.png"
+)
+
+// WebhookClient 透過 forum channel 的 webhook URL 發送訊息（不需要 bot token）
+// 實作與 Client 相同的 Notifier 介面，formatter 產生的 ThreadMessage 可直接使用
+// 限制：webhook 只能發到自己的 channel，不能 archive / rename thread，也不能發 release 公告到其他 channel
+type WebhookClient struct {
+	webhookURL string
+	httpClient *http.Client
+	limiter    *rateLimiter
+	dryRun     bool
+}
+
+// NewWebhookClient 建立 webhook client，webhookURL 為 https://discord.com/api/webhooks/{id}/{token}
+// opts 只使用 Timeout 與 RateLimit，為 nil 時使用預設值
+func NewWebhookClient(webhookURL string, opts *ClientOptions) *WebhookClient {
+	o := DefaultClientOptions()
+	if opts != nil {
+		if opts.Timeout > 0 {
+			o.Timeout = opts.Timeout
+		}
+		if opts.RateLimit > 0 {
+			o.RateLimit = opts.RateLimit
+		}
+	}
+
+	return &WebhookClient{
+		webhookURL: webhookURL,
+		httpClient: &http.Client{
+			Timeout: o.Timeout,
+		},
+		limiter: newRateLimiter(o.RateLimit),
+	}
+}
+
+// EnableDryRun 開啟 dry-run 模式（同 Client.EnableDryRun）
+func (w *WebhookClient) EnableDryRun() {
+	w.dryRun = true
+}
+
+// webhookMessage webhook execute 的 payload：ThreadMessage 加上建立 forum post 用的欄位
+type webhookMessage struct {
+	ThreadMessage
+	ThreadName  string   `json:"thread_name,omitempty"`
+	AppliedTags []string `json:"applied_tags,omitempty"`
+}
+
+// CreateThread 在 forum channel 建立新的 post，回傳 thread ID（即回應訊息的 channel_id）
+func (w *WebhookClient) CreateThread(ctx context.Context, title string, message ThreadMessage, tagIDs ...string) (string, error) {
+	payload := webhookMessage{
+		ThreadMessage: withWebhookIdentity(message),
+		ThreadName:    title,
+		AppliedTags:   tagIDs,
+	}
+
+	if w.dryRun {
+		logDryRun("WebhookCreateThread", "webhook", payload)
+		// 每個 PR 拿到不同的假 thread ID，log 才看得出各 PR 的訊息送往哪個 thread
+		return fmt.Sprintf("dry-run-%d", time.Now().UnixNano()), nil
+	}
+
+	var resp struct {
+		ChannelID string `json:"channel_id"`
+	}
+	if err := w.execute(ctx, "", payload, &resp); err != nil {
+		return "", err
+	}
+	return resp.ChannelID, nil
+}
+
+// PostMessage 在既有的 thread 中發送訊息
+func (w *WebhookClient) PostMessage(ctx context.Context, threadID string, message ThreadMessage) error {
+	payload := webhookMessage{ThreadMessage: withWebhookIdentity(message)}
+
+	if w.dryRun {
+		logDryRun("WebhookPostMessage", threadID, payload)
+		return nil
+	}
+
+	return w.execute(ctx, threadID, payload, nil)
+}
+
+// ArchiveThread webhook 沒有權限修改 thread，no-op
+func (w *WebhookClient) ArchiveThread(ctx context.Context, threadID string) error {
+	return nil
+}
+
+// withWebhookIdentity 沒有指定 username / avatar 時顯示為 GitHub，並補上 AllowedMentions 與長度限制
+func withWebhookIdentity(message ThreadMessage) ThreadMessage {
+	message = prepareMessage(message)
+	// execute webhook 不支援回覆
+	message.MessageReference = nil
+	if message.Username == "" {
+		message.Username = DefaultWebhookUsername
+	}
+	if message.AvatarURL == "" {
+		message.AvatarURL = DefaultWebhookAvatarURL
+	}
+	return message
+}
+
+// execute 呼叫 webhook（wait=true 讓 Discord 回傳建立的訊息），threadID 不為空時發到該 thread
+// 非 2xx 一律回傳 *DiscordAPIError
+func (w *WebhookClient) execute(ctx context.Context, threadID string, payload any, out any) error {
+	query := url.Values{"wait": {"true"}}
+	if threadID != "" {
+		query.Set("thread_id", threadID)
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.webhookURL+"?"+query.Encode(), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := w.limiter.wait(ctx); err != nil {
+		return fmt.Errorf("rate limiter: %w", err)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if retryAfter, global := globalRetryAfter(resp); global {
+		w.limiter.pause(retryAfter)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp.StatusCode, body)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dizzycode1112/github-discord-bridge/pkg/applogger"
+)
+
+func TestWebhookClientUsesCallContext(t *testing.T) {
+	var requests atomic.Int32
+	var threadID atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		threadID.Store(r.URL.Query().Get("thread_id"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","channel_id":"thread-1"}`))
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(server.URL, nil)
+
+	if err := client.PostMessage(context.Background(), "thread-1", ThreadMessage{Content: "hello"}); err != nil {
+		t.Fatalf("PostMessage: %v", err)
+	}
+	if requests.Load() != 1 || threadID.Load() != "thread-1" {
+		t.Fatalf("requests = %d, thread_id = %v; want 1 request to thread-1", requests.Load(), threadID.Load())
+	}
+
+	// 呼叫端的 ctx 已取消（例如 webhook 請求已結束）時不再送出
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := client.PostMessage(ctx, "thread-1", ThreadMessage{Content: "hello"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("PostMessage with cancelled ctx: err = %v, want context.Canceled", err)
+	}
+	if requests.Load() != 1 {
+		t.Fatalf("request sent with cancelled ctx (requests = %d)", requests.Load())
+	}
+}
+
+func TestWebhookClientCancelsInFlightRequest(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewWebhookClient(server.URL, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.CreateThread(ctx, "title", ThreadMessage{Content: "hello"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CreateThread: err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWebhookClientDryRunThreadIDsAreUnique(t *testing.T) {
+	applogger.Init("test")
+
+	client := NewWebhookClient("https://discord.invalid/api/webhooks/1/token", nil)
+	client.EnableDryRun()
+
+	first, err := client.CreateThread(context.Background(), "PR #1", ThreadMessage{Content: "hello"})
+	if err != nil {
+		t.Fatalf("CreateThread: %v", err)
+	}
+	second, err := client.CreateThread(context.Background(), "PR #2", ThreadMessage{Content: "hello"})
+	if err != nil {
+		t.Fatalf("CreateThread: %v", err)
+	}
+	if first == second {
+		t.Fatalf("dry-run CreateThread returned the same thread ID %q for different PRs", first)
+	}
+}
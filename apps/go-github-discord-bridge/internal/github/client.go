@@ -0,0 +1,209 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// GitHubAPIBase github.com 的 REST API base URL
+	GitHubAPIBase = "https://api.github.com"
+)
+
+// Client GitHub REST API client（webhook payload 沒有的資訊才需要呼叫 API）
+type Client struct {
+	token      string
+	apiBase    string
+	httpClient *http.Client
+}
+
+// NewClient 建立 GitHub API client
+// apiBase 為空時使用 github.com；GitHub Enterprise Server 傳入 "https://<host>/api/v3"
+func NewClient(token, apiBase string) *Client {
+	if apiBase == "" {
+		apiBase = GitHubAPIBase
+	}
+	return &Client{
+		token:   token,
+		apiBase: strings.TrimSuffix(apiBase, "/"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// PRFile PR 中單一檔案的變更資訊
+type PRFile struct {
+	Filename  string `json:"filename"`
+	Status    string `json:"status"` // added, removed, modified, renamed
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// ListChangedFiles 取得 PR 變更的檔案路徑，最多回傳 limit 筆
+// repoFullName 格式為 "owner/repo"
+func (c *Client) ListChangedFiles(repoFullName string, prNumber int, limit int) ([]string, error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/files?per_page=%d", c.apiBase, repoFullName, prNumber, limit)
+
+	var files []PRFile
+	if err := c.get(url, &files); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		if len(paths) >= limit {
+			break
+		}
+		paths = append(paths, f.Filename)
+	}
+
+	return paths, nil
+}
+
+// GetPullRequest 取得 PR 目前的狀態
+func (c *Client) GetPullRequest(repoFullName string, prNumber int) (*PullRequest, error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d", c.apiBase, repoFullName, prNumber)
+
+	var pr PullRequest
+	if err := c.get(url, &pr); err != nil {
+		return nil, err
+	}
+
+	return &pr, nil
+}
+
+// WorkflowJob workflow run 中的單一 job
+type WorkflowJob struct {
+	ID         int64          `json:"id"`
+	Name       string         `json:"name"`
+	Conclusion string         `json:"conclusion"` // success, failure, cancelled, skipped...
+	HTMLURL    string         `json:"html_url"`
+	Steps      []WorkflowStep `json:"steps"`
+}
+
+// WorkflowStep job 中的單一 step
+type WorkflowStep struct {
+	Name       string `json:"name"`
+	Conclusion string `json:"conclusion"`
+	Number     int    `json:"number"`
+}
+
+// FailedSteps 回傳失敗的 step 名稱
+func (j WorkflowJob) FailedSteps() []string {
+	var steps []string
+	for _, s := range j.Steps {
+		if s.Conclusion == "failure" {
+			steps = append(steps, s.Name)
+		}
+	}
+	return steps
+}
+
+// ListFailedJobs 取得 workflow run 最近一次 attempt 中失敗的 job
+// repoFullName 格式為 "owner/repo"
+func (c *Client) ListFailedJobs(repoFullName string, runID int) ([]WorkflowJob, error) {
+	url := fmt.Sprintf("%s/repos/%s/actions/runs/%d/jobs?filter=latest&per_page=100", c.apiBase, repoFullName, runID)
+
+	var resp struct {
+		Jobs []WorkflowJob `json:"jobs"`
+	}
+	if err := c.get(url, &resp); err != nil {
+		return nil, err
+	}
+
+	var failed []WorkflowJob
+	for _, job := range resp.Jobs {
+		if job.Conclusion == "failure" {
+			failed = append(failed, job)
+		}
+	}
+
+	return failed, nil
+}
+
+// BridgeCommentMarker 加在 bridge 自己發的 PR 留言中（HTML 註解，GitHub 上不會顯示）
+// 收到帶有這個標記的留言事件時直接略過，避免 bridge 回應自己的留言
+const BridgeCommentMarker = "<!-- github-discord-bridge -->"
+
+// CreateIssueComment 在 PR（issue）下留言，body 會自動加上 BridgeCommentMarker
+// token 沒有寫入權限時回傳 403 的 *APIError（可用 IsForbidden 判斷）
+func (c *Client) CreateIssueComment(repoFullName string, number int, body string) error {
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.apiBase, repoFullName, number)
+
+	payload := map[string]string{"body": body + "\n\n" + BridgeCommentMarker}
+	return c.do("POST", url, payload, nil)
+}
+
+// APIError GitHub API 回傳非 2xx 時的錯誤
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("github API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// IsForbidden token 沒有權限（或被 secondary rate limit 擋下）
+func IsForbidden(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden
+}
+
+// get 發送 GET 請求並將 JSON 回應解析到 out
+func (c *Client) get(url string, out any) error {
+	return c.do("GET", url, nil, out)
+}
+
+// do 發送 API 請求：payload 不為 nil 時以 JSON 送出，out 不為 nil 時解析回應
+// 非 2xx 一律回傳 *APIError
+func (c *Client) do(method, url string, payload any, out any) error {
+	var reqBody io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
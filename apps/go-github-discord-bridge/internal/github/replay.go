@@ -0,0 +1,107 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrReplayedEvent    = errors.New("event timestamp is outside the replay window")
+	ErrMissingTimestamp = errors.New("missing event timestamp")
+)
+
+// ReplayPolicy 以事件時間判斷 webhook 是否為重放（delivery ID 去重之外的額外防護）
+//
+// GitHub 沒有簽署任何時間戳記 header，所以這只是 best-effort：
+//   - 設定 Header 時使用 trusted proxy 加上的時間（unix 秒數或 RFC3339），bridge 必須只能經由該 proxy 存取
+//   - 否則使用 payload 內的時間（pull_request.updated_at 等），這部分有被 HMAC 簽署，但 GitHub 的手動 redeliver 也會被視為過期
+type ReplayPolicy struct {
+	Window time.Duration // 允許的最大事件年齡，0 表示停用
+	Header string        // trusted proxy 加上的時間戳記 header，空字串時使用 payload 內的時間
+	Strict bool          // true 時拒絕過期事件；false 只記錄警告
+}
+
+// Enabled 是否啟用 replay 檢查
+func (p ReplayPolicy) Enabled() bool {
+	return p.Window > 0
+}
+
+// Check 檢查事件時間是否在 Window 內，必須在 signature 驗證通過後才呼叫
+// 設定了 Header 卻沒有帶時回傳 ErrMissingTimestamp；payload 找不到時間時不擋（不是每種事件都有）
+func (p ReplayPolicy) Check(payload []byte, header http.Header, now time.Time) error {
+	if !p.Enabled() {
+		return nil
+	}
+
+	var (
+		ts  time.Time
+		err error
+	)
+	if p.Header != "" {
+		raw := header.Get(p.Header)
+		if raw == "" {
+			return ErrMissingTimestamp
+		}
+		ts, err = parseTimestamp(raw)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrMissingTimestamp, p.Header, err)
+		}
+	} else {
+		var ok bool
+		ts, ok = payloadTimestamp(payload)
+		if !ok {
+			return nil
+		}
+	}
+
+	if age := now.Sub(ts); age > p.Window {
+		return fmt.Errorf("%w (age %s, window %s)", ErrReplayedEvent, age.Round(time.Second), p.Window)
+	}
+	return nil
+}
+
+// parseTimestamp 接受 unix 秒數或 RFC3339
+func parseTimestamp(raw string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+// payloadTimestamp 從 payload 取出最能代表事件發生時間的欄位
+func payloadTimestamp(payload []byte) (time.Time, bool) {
+	var event struct {
+		PullRequest *struct {
+			UpdatedAt time.Time `json:"updated_at"`
+		} `json:"pull_request"`
+		Review *struct {
+			SubmittedAt time.Time `json:"submitted_at"`
+		} `json:"review"`
+		WorkflowRun *struct {
+			UpdatedAt time.Time `json:"updated_at"`
+		} `json:"workflow_run"`
+		Release *struct {
+			PublishedAt time.Time `json:"published_at"`
+		} `json:"release"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return time.Time{}, false
+	}
+
+	var ts time.Time
+	switch {
+	case event.Review != nil:
+		ts = event.Review.SubmittedAt
+	case event.WorkflowRun != nil:
+		ts = event.WorkflowRun.UpdatedAt
+	case event.Release != nil:
+		ts = event.Release.PublishedAt
+	case event.PullRequest != nil:
+		ts = event.PullRequest.UpdatedAt
+	}
+	return ts, !ts.IsZero()
+}
@@ -0,0 +1,124 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+const (
+	// SignaturePrefix X-Hub-Signature-256 header 的固定前綴
+	SignaturePrefix = "sha256="
+
+	// MaxPayloadSize GitHub webhook payload 上限為 25MB，超過的請求不做 HMAC 直接拒絕
+	MaxPayloadSize = 25 << 20
+)
+
+var (
+	ErrMissingSignature   = errors.New("missing signature")
+	ErrMalformedSignature = errors.New("malformed signature")
+	ErrSignatureMismatch  = errors.New("invalid signature")
+	ErrPayloadTooLarge    = errors.New("payload too large")
+)
+
+// SignatureScheme 一種 webhook signature 演算法與對應的 header
+type SignatureScheme struct {
+	Name   string // 設定用的名稱：sha256、sha1
+	Header string
+	prefix string
+	hash   func() hash.Hash
+}
+
+var (
+	// SignatureSHA256 X-Hub-Signature-256（GitHub 建議使用）
+	SignatureSHA256 = SignatureScheme{Name: "sha256", Header: "X-Hub-Signature-256", prefix: SignaturePrefix, hash: sha256.New}
+	// SignatureSHA1 X-Hub-Signature（舊版整合與部分 proxy 只送這個）
+	SignatureSHA1 = SignatureScheme{Name: "sha1", Header: "X-Hub-Signature", prefix: "sha1=", hash: sha1.New}
+
+	// signatureSchemes 所有支援的演算法，依偏好順序排列
+	signatureSchemes = []SignatureScheme{SignatureSHA256, SignatureSHA1}
+)
+
+// ParseSignatureSchemes 將名稱（sha256、sha1）轉成 SignatureScheme，結果依偏好順序（SHA-256 優先）排列
+func ParseSignatureSchemes(names []string) ([]SignatureScheme, error) {
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		known := false
+		for _, scheme := range signatureSchemes {
+			if scheme.Name == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown signature scheme %q (expected sha256 or sha1)", name)
+		}
+		allowed[name] = true
+	}
+
+	var schemes []SignatureScheme
+	for _, scheme := range signatureSchemes {
+		if allowed[scheme.Name] {
+			schemes = append(schemes, scheme)
+		}
+	}
+	if len(schemes) == 0 {
+		return nil, errors.New("no signature scheme enabled")
+	}
+	return schemes, nil
+}
+
+// VerifyRequestSignature 依 schemes 的順序找第一個有帶的 signature header 驗證
+// 只驗證偏好最高的那一個（不會在 SHA-256 不符時退回 SHA-1）；沒有任何可接受的 header 時回傳 ErrMissingSignature
+// secret 為空時一律回傳 error（fail closed），是否跳過驗證由呼叫端決定
+func VerifyRequestSignature(payload []byte, header http.Header, secret string, schemes []SignatureScheme) error {
+	for _, scheme := range schemes {
+		if signature := header.Get(scheme.Header); signature != "" {
+			return verifyHMAC(payload, signature, secret, scheme)
+		}
+	}
+	if secret == "" {
+		return errors.New("webhook secret is not configured")
+	}
+	return ErrMissingSignature
+}
+
+// verifyHMAC 以 scheme 的演算法驗證 "<prefix><hex digest>" 格式的 signature
+func verifyHMAC(payload []byte, signature, secret string, scheme SignatureScheme) error {
+	if secret == "" {
+		return errors.New("webhook secret is not configured")
+	}
+
+	if len(payload) > MaxPayloadSize {
+		return ErrPayloadTooLarge
+	}
+
+	if signature == "" {
+		return ErrMissingSignature
+	}
+
+	if !strings.HasPrefix(signature, scheme.prefix) {
+		return ErrMalformedSignature
+	}
+
+	mac := hmac.New(scheme.hash, []byte(secret))
+	received, err := hex.DecodeString(strings.TrimPrefix(signature, scheme.prefix))
+	if err != nil || len(received) != mac.Size() {
+		return ErrMalformedSignature
+	}
+
+	mac.Write(payload)
+
+	if !hmac.Equal(received, mac.Sum(nil)) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
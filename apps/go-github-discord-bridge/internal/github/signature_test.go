@@ -0,0 +1,101 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"net/http"
+	"testing"
+)
+
+const testSecret = "test-secret"
+
+var testPayload = []byte(`{"action":"opened"}`)
+
+// sign 以 h 計算 payload 的 signature header 值
+func sign(h func() hash.Hash, prefix string, payload []byte) string {
+	mac := hmac.New(h, []byte(testSecret))
+	mac.Write(payload)
+	return prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyRequestSignatureSHA256(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", sign(sha256.New, "sha256=", testPayload))
+
+	if err := VerifyRequestSignature(testPayload, header, testSecret, signatureSchemes); err != nil {
+		t.Fatalf("valid SHA-256 signature rejected: %v", err)
+	}
+	if err := VerifyRequestSignature([]byte(`{"action":"closed"}`), header, testSecret, signatureSchemes); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("tampered payload: err = %v, want ErrSignatureMismatch", err)
+	}
+}
+
+func TestVerifyRequestSignatureSHA1(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Hub-Signature", sign(sha1.New, "sha1=", testPayload))
+
+	if err := VerifyRequestSignature(testPayload, header, testSecret, signatureSchemes); err != nil {
+		t.Fatalf("valid SHA-1 signature rejected: %v", err)
+	}
+
+	header.Set("X-Hub-Signature", "sha256="+hex.EncodeToString(make([]byte, sha1.Size)))
+	if err := VerifyRequestSignature(testPayload, header, testSecret, signatureSchemes); !errors.Is(err, ErrMalformedSignature) {
+		t.Fatalf("wrong prefix: err = %v, want ErrMalformedSignature", err)
+	}
+}
+
+func TestVerifyRequestSignaturePrefersSHA256(t *testing.T) {
+	// 兩個 header 都有時只驗證 SHA-256，不會在 SHA-256 不符時退回 SHA-1
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", sign(sha256.New, "sha256=", []byte("other payload")))
+	header.Set("X-Hub-Signature", sign(sha1.New, "sha1=", testPayload))
+
+	if err := VerifyRequestSignature(testPayload, header, testSecret, signatureSchemes); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("err = %v, want ErrSignatureMismatch", err)
+	}
+}
+
+func TestVerifyRequestSignatureSHA1Disabled(t *testing.T) {
+	schemes, err := ParseSignatureSchemes([]string{"sha256"})
+	if err != nil {
+		t.Fatalf("ParseSignatureSchemes: %v", err)
+	}
+
+	header := http.Header{}
+	header.Set("X-Hub-Signature", sign(sha1.New, "sha1=", testPayload))
+	if err := VerifyRequestSignature(testPayload, header, testSecret, schemes); !errors.Is(err, ErrMissingSignature) {
+		t.Fatalf("SHA-1 only with SHA-1 disabled: err = %v, want ErrMissingSignature", err)
+	}
+
+	header.Set("X-Hub-Signature-256", sign(sha256.New, "sha256=", testPayload))
+	if err := VerifyRequestSignature(testPayload, header, testSecret, schemes); err != nil {
+		t.Fatalf("valid SHA-256 signature rejected with SHA-1 disabled: %v", err)
+	}
+}
+
+func TestVerifyRequestSignatureMissing(t *testing.T) {
+	if err := VerifyRequestSignature(testPayload, http.Header{}, testSecret, signatureSchemes); !errors.Is(err, ErrMissingSignature) {
+		t.Fatalf("err = %v, want ErrMissingSignature", err)
+	}
+}
+
+func TestParseSignatureSchemes(t *testing.T) {
+	schemes, err := ParseSignatureSchemes([]string{"SHA1", " sha256 "})
+	if err != nil {
+		t.Fatalf("ParseSignatureSchemes: %v", err)
+	}
+	if len(schemes) != 2 || schemes[0].Name != "sha256" || schemes[1].Name != "sha1" {
+		t.Fatalf("schemes = %v, want [sha256 sha1]", schemes)
+	}
+
+	if _, err := ParseSignatureSchemes([]string{"md5"}); err == nil {
+		t.Fatal("unknown scheme accepted")
+	}
+	if _, err := ParseSignatureSchemes(nil); err == nil {
+		t.Fatal("empty scheme list accepted")
+	}
+}
@@ -2,35 +2,72 @@ package github
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// payload 中的 html_url 都是完整的絕對網址（GitHub Enterprise Server 也一樣會帶自己的 host），
+// formatter 直接使用即可，不需要依 GITHUB_BASE_URL 改寫
+
 // WebhookPayload 是 GitHub webhook 的主要結構
 type WebhookPayload struct {
-	Action      string       `json:"action"` // opened, synchronize, closed, etc.
-	PullRequest *PullRequest `json:"pull_request,omitempty"`
+	Action            string       `json:"action"` // opened, synchronize, closed, etc.
+	PullRequest       *PullRequest `json:"pull_request,omitempty"`
 	Review            *Review      `json:"review,omitempty"`
 	RequestedReviewer *User        `json:"requested_reviewer,omitempty"`
+	Assignee          *User        `json:"assignee,omitempty"` // assigned / unassigned 的對象（每次只有一位）
 	WorkflowRun       *WorkflowRun `json:"workflow_run,omitempty"`
+	Release           *Release     `json:"release,omitempty"`
+	Changes           *Changes     `json:"changes,omitempty"` // edited 時帶有修改前的值
 	Repository        Repository   `json:"repository"`
 	Sender            User         `json:"sender"`
+
+	// pull_request_review_comment 的行內留言
+	Comment *ReviewComment `json:"comment,omitempty"`
 }
 
 type PullRequest struct {
-	Number    int       `json:"number"`
-	Title     string    `json:"title"`
-	Body      string    `json:"body"`
-	State     string    `json:"state"` // open, closed
-	HTMLURL   string    `json:"html_url"`
-	DiffURL   string    `json:"diff_url"`
-	User      User      `json:"user"`
-	Base      Branch    `json:"base"`
-	Head      Branch    `json:"head"`
-	Merged    bool      `json:"merged"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Additions int       `json:"additions"`
-	Deletions int       `json:"deletions"`
+	Number       int       `json:"number"`
+	Title        string    `json:"title"`
+	Body         string    `json:"body"`
+	State        string    `json:"state"` // open, closed
+	HTMLURL      string    `json:"html_url"`
+	DiffURL      string    `json:"diff_url"`
+	User         User      `json:"user"`
+	Base         Branch    `json:"base"`
+	Head         Branch    `json:"head"`
+	Merged       bool      `json:"merged"`
+	Draft        bool      `json:"draft"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	MergedAt     time.Time `json:"merged_at"` // 未合併時為零值（payload 中為 null）
+	ClosedAt     time.Time `json:"closed_at"` // 未關閉時為零值
+	Additions    int       `json:"additions"`
+	Deletions    int       `json:"deletions"`
+	ChangedFiles int       `json:"changed_files"`
+
+	// MergeableState GitHub 非同步計算的合併狀態：clean、dirty（有衝突）、blocked、behind、unstable…
+	// 還沒算好時為 unknown 或空字串
+	MergeableState string `json:"mergeable_state"`
+}
+
+// MergeableStateDirty mergeable_state 中表示有 merge conflict 的值
+const MergeableStateDirty = "dirty"
+
+// MergeableStateKnown 回傳 mergeable_state 是否已由 GitHub 計算完成
+func (pr *PullRequest) MergeableStateKnown() bool {
+	return pr.MergeableState != "" && pr.MergeableState != "unknown"
+}
+
+// Changes 是 edited action 帶的修改前內容，只有被修改的欄位會出現
+type Changes struct {
+	Title *ChangedValue `json:"title,omitempty"`
+	Body  *ChangedValue `json:"body,omitempty"`
+}
+
+type ChangedValue struct {
+	From string `json:"from"`
 }
 
 type Review struct {
@@ -42,14 +79,41 @@ type Review struct {
 	SubmittedAt time.Time `json:"submitted_at"`
 }
 
+// ReviewComment review 中針對某段 diff 的行內留言
+type ReviewComment struct {
+	ID        int       `json:"id"`
+	User      User      `json:"user"`
+	Body      string    `json:"body"`
+	Path      string    `json:"path"`      // 檔案路徑
+	DiffHunk  string    `json:"diff_hunk"` // 留言所在的 diff 片段，最後一行是被留言的那一行
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type WorkflowRun struct {
-	ID           int              `json:"id"`
-	Name         string           `json:"name"`
-	HeadSHA      string           `json:"head_sha"`
-	Status       string           `json:"status"`     // completed
-	Conclusion   string           `json:"conclusion"` // success, failure, timed_out, cancelled
-	HTMLURL      string           `json:"html_url"`
-	PullRequests []WorkflowRunPR  `json:"pull_requests"`
+	ID           int             `json:"id"`
+	Name         string          `json:"name"`
+	HeadSHA      string          `json:"head_sha"`
+	Status       string          `json:"status"`     // completed
+	Conclusion   string          `json:"conclusion"` // success, failure, timed_out, cancelled
+	HTMLURL      string          `json:"html_url"`
+	Actor        User            `json:"actor"` // 觸發 workflow 的使用者（Dependabot PR 為 dependabot[bot]）
+	PullRequests []WorkflowRunPR `json:"pull_requests"`
+	UpdatedAt    time.Time       `json:"updated_at"` // completed 事件中即為完成時間
+
+	HeadRepository *Repository `json:"head_repository,omitempty"` // 觸發 workflow 的 commit 所在的 repo（fork PR 為 fork）
+}
+
+type Release struct {
+	ID          int       `json:"id"`
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	Author      User      `json:"author"`
+	Prerelease  bool      `json:"prerelease"`
+	Draft       bool      `json:"draft"`
+	PublishedAt time.Time `json:"published_at"`
 }
 
 type WorkflowRunPR struct {
@@ -69,8 +133,27 @@ type User struct {
 }
 
 type Branch struct {
-	Ref string `json:"ref"` // branch name
-	SHA string `json:"sha"`
+	Ref  string      `json:"ref"` // branch name
+	SHA  string      `json:"sha"`
+	Repo *Repository `json:"repo"` // branch 所在的 repo，fork 被刪除時為 null
+}
+
+// IsFromFork 判斷 PR 是否來自 fork（head repo 與 base repo 不同）
+// fork 已被刪除（head.repo 為 null）時也視為 fork
+func (pr *PullRequest) IsFromFork() bool {
+	if pr.Head.Repo == nil || pr.Base.Repo == nil {
+		return pr.Head.Repo == nil
+	}
+	return !strings.EqualFold(pr.Head.Repo.FullName, pr.Base.Repo.FullName)
+}
+
+// IsFromFork 判斷 workflow run 是否由 fork 的 commit 觸發，repoFullName 為 workflow 所在的 repo
+// payload 沒有 head_repository 時無法判斷，視為同一個 repo
+func (wr *WorkflowRun) IsFromFork(repoFullName string) bool {
+	if wr.HeadRepository == nil {
+		return false
+	}
+	return !strings.EqualFold(wr.HeadRepository.FullName, repoFullName)
 }
 
 // GetPRIdentifier 回傳唯一識別這個 PR 的 key
@@ -81,3 +164,16 @@ func (w *WebhookPayload) GetPRIdentifier() string {
 	}
 	return ""
 }
+
+// ParsePRIdentifier 解析 GetPRIdentifier 產生的 key，回傳 "owner/repo" 與 PR 編號
+func ParsePRIdentifier(prID string) (repoFullName string, number int, err error) {
+	idx := strings.LastIndex(prID, "#")
+	if idx <= 0 {
+		return "", 0, fmt.Errorf("invalid PR identifier %q", prID)
+	}
+	number, err = strconv.Atoi(prID[idx+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid PR number in %q: %w", prID, err)
+	}
+	return prID[:idx], number, nil
+}
@@ -0,0 +1,84 @@
+package github
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// workflowRunPayload 只保留 SKIP_FORK_CI 需要的欄位的 workflow_run payload
+func workflowRunPayload(headRepo string) []byte {
+	return []byte(`{
+		"action": "completed",
+		"repository": {"name": "bridge", "full_name": "acme/bridge"},
+		"workflow_run": {
+			"id": 42,
+			"name": "CI",
+			"conclusion": "failure",
+			"pull_requests": [{"number": 7}],
+			"head_repository": {"name": "bridge", "full_name": "` + headRepo + `"}
+		}
+	}`)
+}
+
+// pullRequestPayload 只保留 head / base repo 的 pull_request payload
+func pullRequestPayload(headRepo string) []byte {
+	return []byte(`{
+		"action": "opened",
+		"repository": {"name": "bridge", "full_name": "acme/bridge"},
+		"pull_request": {
+			"number": 7,
+			"head": {"ref": "feature", "repo": {"full_name": "` + headRepo + `"}},
+			"base": {"ref": "main", "repo": {"full_name": "acme/bridge"}}
+		}
+	}`)
+}
+
+func TestWorkflowRunIsFromFork(t *testing.T) {
+	tests := []struct {
+		name     string
+		headRepo string
+		want     bool
+	}{
+		{"same repo", "acme/bridge", false},
+		{"same repo different case", "Acme/Bridge", false},
+		{"cross repo", "contributor/bridge", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload WebhookPayload
+			if err := json.Unmarshal(workflowRunPayload(tt.headRepo), &payload); err != nil {
+				t.Fatalf("failed to parse payload: %v", err)
+			}
+			if got := payload.WorkflowRun.IsFromFork(payload.Repository.FullName); got != tt.want {
+				t.Fatalf("IsFromFork = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowRunWithoutHeadRepository(t *testing.T) {
+	// 舊的 payload 沒有 head_repository 時無法判斷，不能被當成 fork 而漏掉通知
+	wr := WorkflowRun{Name: "CI"}
+	if wr.IsFromFork("acme/bridge") {
+		t.Fatal("workflow run without head_repository treated as fork")
+	}
+}
+
+func TestPullRequestIsFromFork(t *testing.T) {
+	for headRepo, want := range map[string]bool{"acme/bridge": false, "contributor/bridge": true} {
+		var payload WebhookPayload
+		if err := json.Unmarshal(pullRequestPayload(headRepo), &payload); err != nil {
+			t.Fatalf("failed to parse payload: %v", err)
+		}
+		if got := payload.PullRequest.IsFromFork(); got != want {
+			t.Fatalf("head %s: IsFromFork = %v, want %v", headRepo, got, want)
+		}
+	}
+
+	// fork 被刪除時 head.repo 為 null
+	deleted := PullRequest{Base: Branch{Repo: &Repository{FullName: "acme/bridge"}}}
+	if !deleted.IsFromFork() {
+		t.Fatal("PR from a deleted fork not treated as fork")
+	}
+}
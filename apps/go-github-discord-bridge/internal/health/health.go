@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CheckFunc 檢查單一依賴，回傳 nil 表示正常
+type CheckFunc func(ctx context.Context) error
+
+// Result 單一依賴的檢查結果
+type Result struct {
+	Status string `json:"status"` // ok 或 error
+	Error  string `json:"error,omitempty"`
+}
+
+// Checker 依序執行所有註冊的檢查（readiness）
+type Checker struct {
+	names  []string
+	checks map[string]CheckFunc
+}
+
+// NewChecker 建立 Checker
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]CheckFunc)}
+}
+
+// Add 註冊一個依賴檢查，name 會出現在回應中
+func (c *Checker) Add(name string, check CheckFunc) {
+	if _, exists := c.checks[name]; !exists {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = check
+}
+
+// Run 執行所有檢查，任一失敗時 healthy 為 false
+func (c *Checker) Run(ctx context.Context) (healthy bool, results map[string]Result) {
+	healthy = true
+	results = make(map[string]Result, len(c.names))
+	for _, name := range c.names {
+		if err := c.checks[name](ctx); err != nil {
+			healthy = false
+			results[name] = Result{Status: "error", Error: err.Error()}
+			continue
+		}
+		results[name] = Result{Status: "ok"}
+	}
+	return healthy, results
+}
+
+// Cached 把 check 的結果快取 ttl，避免頻繁的 probe 打到外部 API（例如 Discord）
+// 快取期間的呼叫直接回傳上次的結果（成功或失敗）
+func Cached(ttl time.Duration, check CheckFunc) CheckFunc {
+	var (
+		mu      sync.Mutex
+		checked time.Time
+		lastErr error
+	)
+
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !checked.IsZero() && time.Since(checked) < ttl {
+			return lastErr
+		}
+
+		lastErr = check(ctx)
+		// ctx 逾時代表這次 probe 本身太慢，不快取，下次 probe 重新檢查
+		if ctx.Err() == nil {
+			checked = time.Now()
+		}
+		return lastErr
+	}
+}
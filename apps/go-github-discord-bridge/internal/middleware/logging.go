@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"time"
+
+	"dizzycoder1112/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger 每個請求結束後輸出一行結構化 log（取代 gin 預設的文字 log）
+func RequestLogger(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		fields := map[string]any{
+			"method":    c.Request.Method,
+			"path":      c.Request.URL.Path,
+			"status":    c.Writer.Status(),
+			"latencyMs": time.Since(start).Milliseconds(),
+			"requestID": GetRequestID(c),
+			"clientIP":  c.ClientIP(),
+		}
+
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			log.Error("HTTP request", fields)
+		case status >= 400:
+			log.Warn("HTTP request", fields)
+		default:
+			log.Info("HTTP request", fields)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"dizzycoder1112/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 閒置超過 bucketIdleTTL 的 IP（bucket 早已補滿）在下次清理時移除，清理最多每 sweepInterval 一次
+	bucketIdleTTL = 10 * time.Minute
+	sweepInterval = time.Minute
+)
+
+// ipBucket 單一 client IP 的 token bucket
+type ipBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// ipRateLimiter 以 client IP 為 key 的 token bucket，多個 goroutine 同時呼叫也安全
+type ipRateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // 每秒補充的 token 數
+	burst     float64 // bucket 容量
+	buckets   map[string]*ipBucket
+	lastSweep time.Time
+}
+
+func newIPRateLimiter(perSecond float64, burst int) *ipRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &ipRateLimiter{
+		rate:      perSecond,
+		burst:     float64(burst),
+		buckets:   make(map[string]*ipBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow 嘗試為 ip 取得 token，成功回傳 0，否則回傳需要等待的時間（Retry-After 用）
+func (l *ipRateLimiter) allow(ip string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[ip]
+	if !ok {
+		b = &ipBucket{tokens: l.burst, last: now}
+		l.buckets[ip] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep 移除閒置的 bucket，避免大量不同來源 IP 讓 map 無限成長
+func (l *ipRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for ip, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdleTTL {
+			delete(l.buckets, ip)
+		}
+	}
+}
+
+// RateLimit 以 client IP（c.ClientIP，經過 TRUSTED_PROXIES 判斷 X-Forwarded-For）限制請求速率
+// 每個 IP 每秒 perSecond 個請求、最多累積 burst 個，超過時回 429 並帶 Retry-After
+func RateLimit(perSecond float64, burst int, log logger.Logger) gin.HandlerFunc {
+	limiter := newIPRateLimiter(perSecond, burst)
+
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		if wait := limiter.allow(ip); wait > 0 {
+			log.Warn("Rate limit exceeded", "clientIP", ip, "path", c.Request.URL.Path)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(429, gin.H{"error": "too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// IPAllowlist 只允許來自 allowed（IP 或 CIDR）的請求，其他回 403
+// 在讀取 body 之前就擋下，用於只接受 GitHub hook 網段的 webhook 路由；無效的項目會被略過（config 已驗證）
+func IPAllowlist(allowed []string, log logger.Logger) gin.HandlerFunc {
+	var networks []*net.IPNet
+	for _, entry := range allowed {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+
+	return func(c *gin.Context) {
+		ip := net.ParseIP(c.ClientIP())
+		for _, network := range networks {
+			if ip != nil && network.Contains(ip) {
+				c.Next()
+				return
+			}
+		}
+
+		log.Warn("Request from outside the IP allowlist", "clientIP", c.ClientIP(), "path", c.Request.URL.Path)
+		c.AbortWithStatusJSON(403, gin.H{"error": "forbidden"})
+	}
+}
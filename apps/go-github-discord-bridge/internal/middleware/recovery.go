@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"dizzycoder1112/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery 攔截 handler 的 panic（取代 gin.Recovery），以結構化 log 記錄 panic、stack 與請求資訊
+// 並回傳與其他錯誤一致的 JSON 500；需放在 RequestID 之後才拿得到 request ID
+func Recovery(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// http.ErrAbortHandler 是刻意中斷連線，交回 net/http 處理
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			log.Error("Panic recovered", map[string]any{
+				"panic":     fmt.Sprint(recovered),
+				"stack":     string(debug.Stack()),
+				"method":    c.Request.Method,
+				"path":      c.Request.URL.Path,
+				"requestID": GetRequestID(c),
+			})
+
+			// handler 已經寫出 header 時無法再改狀態碼，只中止後續 handler
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal"})
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoveryReturnsJSONAndLogs(t *testing.T) {
+	log := &recordingLogger{}
+	r := gin.New()
+	r.Use(RequestID(), Recovery(log))
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response is not JSON: %q", w.Body.String())
+	}
+	if body["error"] != "internal" {
+		t.Fatalf("body = %v, want {\"error\":\"internal\"}", body)
+	}
+
+	entry, ok := log.find("Panic recovered")
+	if !ok {
+		t.Fatal("panic was not logged")
+	}
+	if entry.level != "error" || len(entry.context) != 1 {
+		t.Fatalf("log entry = %+v, want one error with a context map", entry)
+	}
+	fields, _ := entry.context[0].(map[string]any)
+	for key, want := range map[string]string{"panic": "boom", "method": "GET", "path": "/panic", "requestID": "req-1"} {
+		if fields[key] != want {
+			t.Errorf("log field %s = %v, want %q", key, fields[key], want)
+		}
+	}
+	if stack, _ := fields["stack"].(string); stack == "" {
+		t.Error("log entry has no stack")
+	}
+}
+
+func TestRecoveryPassesThroughWithoutPanic(t *testing.T) {
+	log := &recordingLogger{}
+	r := gin.New()
+	r.Use(Recovery(log))
+	r.GET("/ok", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if len(log.entries) != 0 {
+		t.Fatalf("unexpected log entries: %+v", log.entries)
+	}
+}
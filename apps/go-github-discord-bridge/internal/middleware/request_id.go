@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader 用來傳遞 correlation ID 的 header
+	RequestIDHeader = "X-Request-ID"
+
+	// RequestIDKey 存放在 gin context 的 key
+	RequestIDKey = "requestID"
+)
+
+// RequestID 沿用上游帶來的 X-Request-ID，沒有就產生一個新的
+// 並寫回 response header，方便跨服務追蹤同一個請求
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// GetRequestID 從 gin context 取出 request ID（沒有則回傳空字串）
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
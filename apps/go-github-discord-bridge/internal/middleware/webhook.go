@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"dizzycode1112/github-discord-bridge/internal/github"
+	"dizzycoder1112/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// GitHub webhook 帶的 header
+	GitHubEventHeader    = "X-GitHub-Event"
+	GitHubDeliveryHeader = "X-GitHub-Delivery"
+
+	// WebhookKey 驗證過的 webhook 存放在 gin context 的 key
+	WebhookKey = "githubWebhook"
+)
+
+// Webhook 是通過驗證的 GitHub webhook 請求
+type Webhook struct {
+	Event      string // X-GitHub-Event
+	DeliveryID string // X-GitHub-Delivery
+	Body       []byte // 原始 body（已驗證 signature）
+}
+
+// GitHubWebhook 集中驗證 GitHub webhook 請求，不合格的請求在進到 handler 前就回 4xx：
+//   - body 超過 maxBodyBytes → 413
+//   - 缺少 X-GitHub-Event / X-GitHub-Delivery 或 signature 格式錯誤 → 400
+//   - 沒有可接受的 signature header 或 signature 不符 → 401
+//   - replay.Strict 且事件時間超出 replay window → 401
+//
+// secret 為空時跳過 signature 驗證（啟動時已警告）；schemes 為接受的 signature 演算法（偏好順序）
+// 通過後把 Webhook 存進 context，handler 用 GetWebhook 取得
+func GitHubWebhook(secret string, schemes []github.SignatureScheme, replay github.ReplayPolicy, maxBodyBytes int64, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event := c.GetHeader(GitHubEventHeader)
+		deliveryID := c.GetHeader(GitHubDeliveryHeader)
+		if event == "" || deliveryID == "" {
+			log.Warn("Webhook missing required headers", "event", event, "deliveryID", deliveryID)
+			c.AbortWithStatusJSON(400, gin.H{"error": "missing X-GitHub-Event or X-GitHub-Delivery header"})
+			return
+		}
+
+		// 限制 body 大小，避免異常的請求把整個 process 的記憶體吃光
+		// signature 驗證與 JSON 解析都只作用在這段有上限的 bytes 上
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes)
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				log.Warn("Webhook body too large", "limit", maxBodyBytes, "deliveryID", deliveryID)
+				c.AbortWithStatusJSON(413, gin.H{"error": "payload too large"})
+				return
+			}
+			c.AbortWithStatusJSON(400, gin.H{"error": "failed to read body"})
+			return
+		}
+
+		if secret != "" {
+			if err := github.VerifyRequestSignature(body, c.Request.Header, secret, schemes); err != nil {
+				log.Warn("Webhook signature verification failed", "error", err, "deliveryID", deliveryID)
+				switch {
+				case errors.Is(err, github.ErrPayloadTooLarge):
+					c.AbortWithStatusJSON(413, gin.H{"error": err.Error()})
+				case errors.Is(err, github.ErrMalformedSignature):
+					c.AbortWithStatusJSON(400, gin.H{"error": err.Error()})
+				default:
+					c.AbortWithStatusJSON(401, gin.H{"error": err.Error()})
+				}
+				return
+			}
+		}
+
+		// 時間只有在 signature 驗證後才可信，所以放在 HMAC 之後檢查
+		if err := replay.Check(body, c.Request.Header, time.Now()); err != nil {
+			if replay.Strict {
+				log.Warn("Webhook rejected by replay protection", "error", err, "deliveryID", deliveryID)
+				c.AbortWithStatusJSON(401, gin.H{"error": err.Error()})
+				return
+			}
+			log.Warn("Webhook failed replay check (not enforced)", "error", err, "deliveryID", deliveryID)
+		}
+
+		c.Set(WebhookKey, &Webhook{
+			Event:      event,
+			DeliveryID: deliveryID,
+			Body:       body,
+		})
+
+		c.Next()
+	}
+}
+
+// GetWebhook 從 gin context 取出驗證過的 webhook（沒有經過 GitHubWebhook 則回傳 nil）
+func GetWebhook(c *gin.Context) *Webhook {
+	v, ok := c.Get(WebhookKey)
+	if !ok {
+		return nil
+	}
+	webhook, _ := v.(*Webhook)
+	return webhook
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"dizzycode1112/github-discord-bridge/internal/github"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testSecret = "test-secret"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// logEntry recordingLogger 記錄的一筆 log
+type logEntry struct {
+	level   string
+	msg     string
+	context []any
+}
+
+// recordingLogger 把 log 記在記憶體中的 logger.Logger，測試用來檢查有沒有寫 log
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) record(level, msg string, context []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, context: context})
+}
+
+func (l *recordingLogger) Info(msg string, context ...any)  { l.record("info", msg, context) }
+func (l *recordingLogger) Error(msg string, context ...any) { l.record("error", msg, context) }
+func (l *recordingLogger) Warn(msg string, context ...any)  { l.record("warn", msg, context) }
+func (l *recordingLogger) Debug(msg string, context ...any) { l.record("debug", msg, context) }
+func (l *recordingLogger) Flush() error                     { return nil }
+
+// find 回傳第一筆 msg 相符的 log
+func (l *recordingLogger) find(msg string) (logEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, entry := range l.entries {
+		if entry.msg == msg {
+			return entry, true
+		}
+	}
+	return logEntry{}, false
+}
+
+// sign 以 SHA-256 計算 X-Hub-Signature-256 header 的值
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// serveWebhook 以 GitHubWebhook 處理一個帶有 signature 的請求，回傳 response 與 handler 收到的 webhook
+func serveWebhook(t *testing.T, body []byte, maxBodyBytes int64) (*httptest.ResponseRecorder, *Webhook) {
+	t.Helper()
+
+	var received *Webhook
+	r := gin.New()
+	r.POST("/webhook/github",
+		GitHubWebhook(testSecret, []github.SignatureScheme{github.SignatureSHA256}, github.ReplayPolicy{}, maxBodyBytes, &recordingLogger{}),
+		func(c *gin.Context) {
+			received = GetWebhook(c)
+			c.JSON(200, gin.H{"status": "processed"})
+		},
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook/github", bytes.NewReader(body))
+	req.Header.Set(GitHubEventHeader, "pull_request")
+	req.Header.Set(GitHubDeliveryHeader, "delivery-1")
+	req.Header.Set(github.SignatureSHA256.Header, sign(body))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w, received
+}
+
+func TestGitHubWebhookRejectsOversizedBody(t *testing.T) {
+	// 簽章正確但超過上限，且不是合法的 JSON：必須在驗證 signature、解析 JSON 之前就回 413
+	body := []byte("{" + strings.Repeat("x", 2048))
+
+	w, received := serveWebhook(t, body, 1024)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", w.Code)
+	}
+	if received != nil {
+		t.Fatal("handler was called for an oversized body")
+	}
+}
+
+func TestGitHubWebhookAcceptsBodyWithinLimit(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+
+	w, received := serveWebhook(t, body, int64(len(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %s)", w.Code, w.Body.String())
+	}
+	if received == nil || !bytes.Equal(received.Body, body) {
+		t.Fatalf("handler received %+v, want body %s", received, body)
+	}
+}
+
+func TestGitHubWebhookVerifiesLimitedBytes(t *testing.T) {
+	// signature 只涵蓋上限內的前段 bytes：剩下的部分會讓 body 超過上限，不能因為前段簽章正確就通過
+	prefix := []byte(`{"action":"opened"}`)
+	body := append(append([]byte{}, prefix...), strings.Repeat(" ", 64)...)
+
+	r := gin.New()
+	called := false
+	r.POST("/webhook/github",
+		GitHubWebhook(testSecret, []github.SignatureScheme{github.SignatureSHA256}, github.ReplayPolicy{}, int64(len(prefix)), &recordingLogger{}),
+		func(c *gin.Context) { called = true },
+	)
+	req := httptest.NewRequest(http.MethodPost, "/webhook/github", bytes.NewReader(body))
+	req.Header.Set(GitHubEventHeader, "pull_request")
+	req.Header.Set(GitHubDeliveryHeader, "delivery-1")
+	req.Header.Set(github.SignatureSHA256.Header, sign(prefix))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge || called {
+		t.Fatalf("status = %d, handler called = %v; want 413 without calling the handler", w.Code, called)
+	}
+}
@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"context"
+
+	"dizzycode1112/github-discord-bridge/internal/discord"
+)
+
+// Notifier 定義通知後端（Discord、Teams…）需要提供的操作
+// 訊息格式統一使用 discord.ThreadMessage，由各後端自行轉換
+// 所有操作都接受 ctx（通常是 webhook 請求的 context），取消或逾時時中止進行中的 API 呼叫
+type Notifier interface {
+	// CreateThread 建立新的 thread，回傳 thread ID（存進 storage 做 PR 對應）
+	CreateThread(ctx context.Context, title string, message discord.ThreadMessage, tagIDs ...string) (string, error)
+
+	// PostMessage 在已存在的 thread 中發送訊息
+	PostMessage(ctx context.Context, threadID string, message discord.ThreadMessage) error
+
+	// ArchiveThread 關閉 thread（不支援的後端可以是 no-op）
+	ArchiveThread(ctx context.Context, threadID string) error
+}
+
+// ThreadRenamer 支援修改 thread 標題的後端
+type ThreadRenamer interface {
+	RenameThread(ctx context.Context, threadID, title string) error
+}
+
+// RepoTagger 支援用 repo 分類 thread 的後端（例如 Discord forum tags）
+type RepoTagger interface {
+	GetOrCreateRepoTag(ctx context.Context, repoName string) (string, error)
+}
+
+// StatusEditor 支援發送後再原地修改訊息的後端（PR 狀態訊息用）
+type StatusEditor interface {
+	// CreateMessage 發送訊息並回傳 message ID
+	CreateMessage(ctx context.Context, threadID string, message discord.ThreadMessage) (messageID string, err error)
+
+	// EditMessage 修改已發送的訊息，訊息不存在時回傳 404 錯誤
+	EditMessage(ctx context.Context, threadID, messageID string, message discord.ThreadMessage) error
+}
+
+// ThreadLinker 可以產生 thread 連結的後端（POST_BACK_TO_GITHUB 用）
+type ThreadLinker interface {
+	ThreadURL(ctx context.Context, threadID string) (string, error)
+}
+
+// Verifier 可以檢查 credentials 與目標 channel 是否仍然有效的後端（health check 用）
+type Verifier interface {
+	Verify(ctx context.Context) error
+}
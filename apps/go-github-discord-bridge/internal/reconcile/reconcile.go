@@ -0,0 +1,88 @@
+package reconcile
+
+import (
+	"context"
+	"time"
+
+	"dizzycode1112/github-discord-bridge/internal/github"
+	"dizzycode1112/github-discord-bridge/internal/notifier"
+	"dizzycode1112/github-discord-bridge/internal/storage"
+	"dizzycode1112/github-discord-bridge/pkg/applogger"
+)
+
+// Summary 一次 reconcile 的結果統計
+type Summary struct {
+	Checked  int // 檢查的開啟中 mapping 數
+	Archived int // GitHub 上已 merged/closed，補做 archive 的數量
+	Failed   int // 查詢或 archive 失敗的數量
+}
+
+// Run 啟動時補做 bridge 停機期間漏掉的 archive
+// 對 store 中每個未關閉的 PR 查詢 GitHub 目前狀態，已 merged/closed 的就 archive thread 並 MarkAsClosed
+// interval 為每次 GitHub API 呼叫之間的間隔，避免撞到 rate limit；ctx 取消時提前結束
+func Run(ctx context.Context, store storage.Store, n notifier.Notifier, gh *github.Client, interval time.Duration) Summary {
+	log := applogger.Log
+	var summary Summary
+
+	prIDs, err := store.List(ctx)
+	if err != nil {
+		log.Error("Failed to list open PRs for reconciliation", "error", err)
+		return summary
+	}
+
+	for i, prID := range prIDs {
+		if i > 0 && interval > 0 {
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				log.Info("Reconciliation cancelled", "remaining", len(prIDs)-i)
+				return summary
+			}
+		}
+		summary.Checked++
+
+		threadID, exists, err := store.Get(ctx, prID)
+		if err != nil {
+			log.Error("Failed to get thread for reconciliation", "prID", prID, "error", err)
+			summary.Failed++
+			continue
+		}
+		if !exists {
+			continue
+		}
+
+		repoFullName, number, err := github.ParsePRIdentifier(prID)
+		if err != nil {
+			log.Warn("Skipping unrecognized mapping key", "prID", prID, "error", err)
+			summary.Failed++
+			continue
+		}
+
+		pr, err := gh.GetPullRequest(repoFullName, number)
+		if err != nil {
+			log.Error("Failed to fetch PR state", "prID", prID, "error", err)
+			summary.Failed++
+			continue
+		}
+
+		if pr.State != "closed" {
+			continue
+		}
+
+		if err := n.ArchiveThread(ctx, threadID); err != nil {
+			log.Error("Failed to archive thread during reconciliation", "prID", prID, "threadID", threadID, "error", err)
+			summary.Failed++
+			continue
+		}
+		if err := store.MarkAsClosed(ctx, prID); err != nil {
+			log.Error("Failed to mark as closed during reconciliation", "prID", prID, "error", err)
+			summary.Failed++
+			continue
+		}
+
+		log.Info("Reconciled stale thread", "prID", prID, "threadID", threadID, "merged", pr.Merged)
+		summary.Archived++
+	}
+
+	return summary
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// CIResult 合併 CI 通知時暫存的一筆 workflow run 結果
+type CIResult struct {
+	Workflow   string    `json:"workflow"`
+	Conclusion string    `json:"conclusion"`
+	URL        string    `json:"url"`
+	HeadSHA    string    `json:"headSha"`
+	PRIDs      []string  `json:"prIds"` // 關聯的 PR identifier（owner/repo#123）
+	At         time.Time `json:"at"`
+}
+
+// CIStore 暫存同一個 commit 的 CI 結果，等合併的時間窗結束後一起發送
+type CIStore interface {
+	// AppendCIResult 加入一筆結果，回傳加入後的筆數（1 表示是這個時間窗的第一筆，由呼叫端排程發送）
+	AppendCIResult(ctx context.Context, key string, result CIResult, ttl time.Duration) (int64, error)
+
+	// TakeCIResults 取出並清除 key 的所有結果
+	// followUp 為 true 表示這個 key 在 FollowUpTTL 內已經發送過一次（時間窗之後才到的結果）
+	TakeCIResults(ctx context.Context, key string) (results []CIResult, followUp bool, err error)
+}
+
+const (
+	// ciKeyPrefix 待發送的 CI 結果存在 list "ci:<key>"，key 為 "<repo>@<sha>"
+	ciKeyPrefix = "ci:"
+	// ciSentKeyPrefix 標記 key 已發送過，之後到的結果以補發的方式發送
+	ciSentKeyPrefix = "ci_sent:"
+
+	// FollowUpTTL 發送過的標記保留多久（比最慢的 workflow 還久即可）
+	FollowUpTTL = 6 * time.Hour
+)
+
+// AppendCIResult 把結果加到 key 的 list，並設定 ttl（程序在發送前掛掉時自動清除）
+func (r *RedisStore) AppendCIResult(ctx context.Context, key string, result CIResult, ttl time.Duration) (int64, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal CI result: %w", err)
+	}
+
+	var count int64
+	err = r.withRetry(ctx, func() error {
+		pipe := r.client.TxPipeline()
+		push := pipe.RPush(ctx, r.key(ciKeyPrefix+key), data)
+		pipe.Expire(ctx, r.key(ciKeyPrefix+key), ttl)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		count = push.Val()
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to append CI result: %w", err)
+	}
+	return count, nil
+}
+
+// TakeCIResults 取出並刪除 key 的 list，同時設定已發送的標記
+func (r *RedisStore) TakeCIResults(ctx context.Context, key string) ([]CIResult, bool, error) {
+	var (
+		items     []string
+		firstSend bool
+	)
+	err := r.withRetry(ctx, func() error {
+		pipe := r.client.TxPipeline()
+		lrange := pipe.LRange(ctx, r.key(ciKeyPrefix+key), 0, -1)
+		pipe.Del(ctx, r.key(ciKeyPrefix+key))
+		sent := pipe.SetNX(ctx, r.key(ciSentKeyPrefix+key), 1, FollowUpTTL)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		items = lrange.Val()
+		firstSend = sent.Val()
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to take CI results: %w", err)
+	}
+
+	results := make([]CIResult, 0, len(items))
+	for _, item := range items {
+		var result CIResult
+		if err := json.Unmarshal([]byte(item), &result); err != nil {
+			continue
+		}
+		results = append(results, result)
+	}
+	return results, !firstSend, nil
+}
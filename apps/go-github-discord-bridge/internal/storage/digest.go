@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DigestEntry digest 模式下累積的一筆 PR 事件
+type DigestEntry struct {
+	Repo   string    `json:"repo"`   // owner/repo
+	Kind   string    `json:"kind"`   // opened, merged, closed
+	Number int       `json:"number"` // PR 編號
+	Title  string    `json:"title"`
+	URL    string    `json:"url"`
+	Author string    `json:"author"`
+	At     time.Time `json:"at"`
+}
+
+// DigestStore 儲存尚未發送的 digest 事件
+type DigestStore interface {
+	// AppendDigest 累積一筆事件，依 repo 與日期分組
+	AppendDigest(ctx context.Context, entry DigestEntry) error
+
+	// TakeDigests 取出並清除所有待發送的事件，依 repo 分組
+	// 部分事件取出失敗時仍回傳已取出的部分，連同錯誤一起回傳
+	TakeDigests(ctx context.Context) (map[string][]DigestEntry, error)
+}
+
+const (
+	// digestKeyPrefix 待發送事件存在 list "digest:<repo>:<YYYY-MM-DD>"
+	digestKeyPrefix = "digest:"
+	// digestKeysKey 所有待發送 digest list 的索引
+	digestKeysKey = indexKeyPrefix + "digest_keys"
+)
+
+// AppendDigest 把事件加到當天的 digest list
+func (r *RedisStore) AppendDigest(ctx context.Context, entry DigestEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal digest entry: %w", err)
+	}
+
+	key := r.key(fmt.Sprintf("%s%s:%s", digestKeyPrefix, entry.Repo, entry.At.Format("2006-01-02")))
+	err = r.withRetry(ctx, func() error {
+		pipe := r.client.TxPipeline()
+		pipe.RPush(ctx, key, data)
+		pipe.SAdd(ctx, r.key(digestKeysKey), key)
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to append digest entry: %w", err)
+	}
+	return nil
+}
+
+// TakeDigests 取出所有待發送的 digest（含先前日期未發送的）並刪除
+// 某個 key 失敗時繼續處理其他 key：已取出的 list 已從 Redis 刪除，必須交給呼叫端發送或放回
+func (r *RedisStore) TakeDigests(ctx context.Context) (map[string][]DigestEntry, error) {
+	var keys []string
+	err := r.withRetry(ctx, func() error {
+		var err error
+		keys, err = r.client.SMembers(ctx, r.key(digestKeysKey)).Result()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list digest keys: %w", err)
+	}
+
+	digests := make(map[string][]DigestEntry)
+	var errs []error
+	for _, key := range keys {
+		var items []string
+		err := r.withRetry(ctx, func() error {
+			pipe := r.client.TxPipeline()
+			lrange := pipe.LRange(ctx, key, 0, -1)
+			pipe.Del(ctx, key)
+			pipe.SRem(ctx, r.key(digestKeysKey), key)
+			if _, err := pipe.Exec(ctx); err != nil {
+				return err
+			}
+			items = lrange.Val()
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to take digest %s: %w", key, err))
+			continue
+		}
+
+		for _, item := range items {
+			var entry DigestEntry
+			if err := json.Unmarshal([]byte(item), &entry); err != nil {
+				continue
+			}
+			digests[entry.Repo] = append(digests[entry.Repo], entry)
+		}
+	}
+
+	return digests, errors.Join(errs...)
+}
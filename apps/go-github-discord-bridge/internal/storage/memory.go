@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore 只存在程序記憶體中的 storage，重啟後資料全部消失
+// 用於 DRY_RUN（假的 thread ID 不能寫進正式的 Redis）與測試；TTL 的語意與 RedisStore 相同
+type MemoryStore struct {
+	mu        sync.Mutex
+	mappings  map[string]memoryEntry             // prID → threadID
+	status    map[string]memoryEntry             // prID → status message ID
+	mergeable map[string]memoryEntry             // prID → mergeable_state
+	reviews   map[string]map[string]ReviewRecord // prID → reviewer → record
+	reviewTTL map[string]time.Time               // prID → review 狀態的到期時間（PR 關閉後設定）
+	locks     map[string]memoryLock
+	ci        map[string][]CIResult
+	ciSent    map[string]time.Time // key → 已發送標記的到期時間
+	digests   map[string][]DigestEntry
+	lockSeq   uint64
+}
+
+// memoryEntry 一個值與它的到期時間（zero value 表示沒有 TTL）
+type memoryEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// memoryLock 持有中的鎖，token 用來避免釋放到別人重新取得的鎖
+type memoryLock struct {
+	token     uint64
+	expiresAt time.Time
+}
+
+// NewMemoryStore 建立空的記憶體 storage
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mappings:  make(map[string]memoryEntry),
+		status:    make(map[string]memoryEntry),
+		mergeable: make(map[string]memoryEntry),
+		reviews:   make(map[string]map[string]ReviewRecord),
+		reviewTTL: make(map[string]time.Time),
+		locks:     make(map[string]memoryLock),
+		ci:        make(map[string][]CIResult),
+		ciSent:    make(map[string]time.Time),
+		digests:   make(map[string][]DigestEntry),
+	}
+}
+
+// expired entry 有 TTL 且已到期
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// lookup 取得未到期的值，順便清掉已到期的 entry（呼叫端需持有 mu）
+func lookup(entries map[string]memoryEntry, key string, now time.Time) (memoryEntry, bool) {
+	entry, ok := entries[key]
+	if ok && entry.expired(now) {
+		delete(entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, ok
+}
+
+// Set 儲存 PR → Thread 對應，不設定 TTL
+func (m *MemoryStore) Set(ctx context.Context, prID, threadID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mappings[prID] = memoryEntry{value: threadID}
+	return nil
+}
+
+// Get 取得對應的 Thread ID
+func (m *MemoryStore) Get(ctx context.Context, prID string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := lookup(m.mappings, prID, time.Now())
+	return entry.value, ok, nil
+}
+
+// Delete 刪除對應關係與狀態訊息、mergeable_state
+func (m *MemoryStore) Delete(ctx context.Context, prID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.mappings, prID)
+	delete(m.status, prID)
+	delete(m.mergeable, prID)
+	return nil
+}
+
+// MarkAsClosed 對應關係、review 狀態、狀態訊息與 mergeable_state 一起設定 7 天 TTL
+func (m *MemoryStore) MarkAsClosed(ctx context.Context, prID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := lookup(m.mappings, prID, now)
+	if !ok {
+		return nil
+	}
+
+	expiresAt := now.Add(ClosedPRTTL)
+	entry.expiresAt = expiresAt
+	m.mappings[prID] = entry
+	for _, entries := range []map[string]memoryEntry{m.status, m.mergeable} {
+		if e, ok := lookup(entries, prID, now); ok {
+			e.expiresAt = expiresAt
+			entries[prID] = e
+		}
+	}
+	if _, ok := m.reviews[prID]; ok {
+		m.reviewTTL[prID] = expiresAt
+	}
+	return nil
+}
+
+// SetReview 記錄 reviewer 的 review 狀態
+func (m *MemoryStore) SetReview(ctx context.Context, prID, reviewer string, record ReviewRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.reviews[prID] == nil {
+		m.reviews[prID] = make(map[string]ReviewRecord)
+	}
+	m.reviews[prID][reviewer] = record
+	return nil
+}
+
+// GetReview 取得 reviewer 最後的 review 狀態
+func (m *MemoryStore) GetReview(ctx context.Context, prID, reviewer string) (ReviewRecord, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if expiresAt, ok := m.reviewTTL[prID]; ok && !time.Now().Before(expiresAt) {
+		delete(m.reviews, prID)
+		delete(m.reviewTTL, prID)
+	}
+	record, ok := m.reviews[prID][reviewer]
+	return record, ok, nil
+}
+
+// SetStatusMessage 記錄狀態訊息的 message ID，沿用 PR mapping 的 TTL
+func (m *MemoryStore) SetStatusMessage(ctx context.Context, prID, messageID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status[prID] = memoryEntry{value: messageID, expiresAt: m.mappingExpiry(prID)}
+	return nil
+}
+
+// GetStatusMessage 取得狀態訊息的 message ID
+func (m *MemoryStore) GetStatusMessage(ctx context.Context, prID string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := lookup(m.status, prID, time.Now())
+	return entry.value, ok, nil
+}
+
+// SwapMergeableState 寫入新的 mergeable_state 並回傳舊值，沿用 PR mapping 的 TTL
+func (m *MemoryStore) SwapMergeableState(ctx context.Context, prID, state string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous, _ := lookup(m.mergeable, prID, time.Now())
+	m.mergeable[prID] = memoryEntry{value: state, expiresAt: m.mappingExpiry(prID)}
+	return previous.value, nil
+}
+
+// mappingExpiry 回傳 PR mapping 的到期時間，沒有 mapping 或沒有 TTL 時為 zero value（呼叫端需持有 mu）
+func (m *MemoryStore) mappingExpiry(prID string) time.Time {
+	entry, _ := lookup(m.mappings, prID, time.Now())
+	return entry.expiresAt
+}
+
+// List 回傳所有未關閉（沒有 TTL）的 PR identifier
+func (m *MemoryStore) List(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	prIDs := make([]string, 0, len(m.mappings))
+	for prID := range m.mappings {
+		entry, ok := lookup(m.mappings, prID, now)
+		if ok && entry.expiresAt.IsZero() {
+			prIDs = append(prIDs, prID)
+		}
+	}
+	return prIDs, nil
+}
+
+// TTL 回傳對應關係剩餘的 TTL，未關閉時回傳 0
+func (m *MemoryStore) TTL(ctx context.Context, prID string) (time.Duration, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	entry, ok := lookup(m.mappings, prID, now)
+	if !ok {
+		return 0, false, nil
+	}
+	if entry.expiresAt.IsZero() {
+		return 0, true, nil
+	}
+	return entry.expiresAt.Sub(now), true, nil
+}
+
+// Lock 取得 ttl 後自動釋放的鎖，只在同一個程序內互斥
+func (m *MemoryStore) Lock(ctx context.Context, key string, ttl time.Duration) (bool, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if lock, ok := m.locks[key]; ok && now.Before(lock.expiresAt) {
+		return false, func() {}, nil
+	}
+	m.lockSeq++
+	token := m.lockSeq
+	m.locks[key] = memoryLock{token: token, expiresAt: now.Add(ttl)}
+
+	var once sync.Once
+	unlock := func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if m.locks[key].token == token {
+				delete(m.locks, key)
+			}
+		})
+	}
+	return true, unlock, nil
+}
+
+// Ping 記憶體 storage 永遠可用
+func (m *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close 沒有需要釋放的資源，與 RedisStore 保持相同的介面
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// AppendCIResult 加入一筆 CI 結果，回傳加入後的筆數
+// 記憶體 storage 不會在程序掛掉後殘留，ttl 不使用
+func (m *MemoryStore) AppendCIResult(ctx context.Context, key string, result CIResult, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ci[key] = append(m.ci[key], result)
+	return int64(len(m.ci[key])), nil
+}
+
+// TakeCIResults 取出並清除 key 的結果，同時設定已發送的標記
+func (m *MemoryStore) TakeCIResults(ctx context.Context, key string) ([]CIResult, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	results := m.ci[key]
+	delete(m.ci, key)
+
+	now := time.Now()
+	sentUntil, sent := m.ciSent[key]
+	followUp := sent && now.Before(sentUntil)
+	if !followUp {
+		m.ciSent[key] = now.Add(FollowUpTTL)
+	}
+	if results == nil {
+		results = []CIResult{}
+	}
+	return results, followUp, nil
+}
+
+// AppendDigest 累積一筆 digest 事件
+func (m *MemoryStore) AppendDigest(ctx context.Context, entry DigestEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.digests[entry.Repo] = append(m.digests[entry.Repo], entry)
+	return nil
+}
+
+// TakeDigests 取出並清除所有待發送的 digest，依 repo 分組
+func (m *MemoryStore) TakeDigests(ctx context.Context) (map[string][]DigestEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	digests := m.digests
+	m.digests = make(map[string][]DigestEntry)
+	return digests, nil
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreMapping(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	if _, exists, err := store.Get(ctx, "owner/repo#1"); err != nil || exists {
+		t.Fatalf("Get on empty store = exists %v, err %v", exists, err)
+	}
+
+	if err := store.Set(ctx, "owner/repo#1", "thread-1"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	threadID, exists, err := store.Get(ctx, "owner/repo#1")
+	if err != nil || !exists || threadID != "thread-1" {
+		t.Fatalf("Get = %q, %v, %v; want thread-1", threadID, exists, err)
+	}
+
+	prIDs, _ := store.List(ctx)
+	if len(prIDs) != 1 || prIDs[0] != "owner/repo#1" {
+		t.Fatalf("List = %v, want [owner/repo#1]", prIDs)
+	}
+
+	if err := store.MarkAsClosed(ctx, "owner/repo#1"); err != nil {
+		t.Fatalf("MarkAsClosed: %v", err)
+	}
+	ttl, exists, _ := store.TTL(ctx, "owner/repo#1")
+	if !exists || ttl <= 0 || ttl > ClosedPRTTL {
+		t.Fatalf("TTL after close = %s, %v; want (0, %s]", ttl, exists, ClosedPRTTL)
+	}
+	if prIDs, _ := store.List(ctx); len(prIDs) != 0 {
+		t.Fatalf("List after close = %v, want empty", prIDs)
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	store.Set(ctx, "owner/repo#1", "thread-1")
+	store.SetStatusMessage(ctx, "owner/repo#1", "message-1")
+	store.MarkAsClosed(ctx, "owner/repo#1")
+
+	// 把到期時間移到過去，模擬 TTL 到期
+	store.mu.Lock()
+	for _, entries := range []map[string]memoryEntry{store.mappings, store.status} {
+		entry := entries["owner/repo#1"]
+		entry.expiresAt = time.Now().Add(-time.Second)
+		entries["owner/repo#1"] = entry
+	}
+	store.mu.Unlock()
+
+	if _, exists, _ := store.Get(ctx, "owner/repo#1"); exists {
+		t.Fatal("mapping still exists after TTL")
+	}
+	if _, exists, _ := store.GetStatusMessage(ctx, "owner/repo#1"); exists {
+		t.Fatal("status message still exists after TTL")
+	}
+}
+
+func TestMemoryStoreSwapMergeableState(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	previous, _ := store.SwapMergeableState(ctx, "owner/repo#1", "dirty")
+	if previous != "" {
+		t.Fatalf("first swap returned %q, want empty", previous)
+	}
+	previous, _ = store.SwapMergeableState(ctx, "owner/repo#1", "clean")
+	if previous != "dirty" {
+		t.Fatalf("second swap returned %q, want dirty", previous)
+	}
+}
+
+func TestMemoryStoreLock(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	acquired, unlock, err := store.Lock(ctx, "thread:owner/repo#1", time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("first Lock = %v, %v; want acquired", acquired, err)
+	}
+	if acquired, _, _ := store.Lock(ctx, "thread:owner/repo#1", time.Minute); acquired {
+		t.Fatal("second Lock acquired a held lock")
+	}
+
+	unlock()
+	acquired, unlock, _ = store.Lock(ctx, "thread:owner/repo#1", time.Minute)
+	if !acquired {
+		t.Fatal("Lock not acquired after unlock")
+	}
+	unlock()
+}
+
+func TestMemoryStoreCIResults(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	count, _ := store.AppendCIResult(ctx, "owner/repo@abc", CIResult{Workflow: "test"}, time.Minute)
+	if count != 1 {
+		t.Fatalf("first AppendCIResult count = %d, want 1", count)
+	}
+	count, _ = store.AppendCIResult(ctx, "owner/repo@abc", CIResult{Workflow: "lint"}, time.Minute)
+	if count != 2 {
+		t.Fatalf("second AppendCIResult count = %d, want 2", count)
+	}
+
+	results, followUp, _ := store.TakeCIResults(ctx, "owner/repo@abc")
+	if len(results) != 2 || followUp {
+		t.Fatalf("TakeCIResults = %d results, followUp %v; want 2, false", len(results), followUp)
+	}
+
+	store.AppendCIResult(ctx, "owner/repo@abc", CIResult{Workflow: "deploy"}, time.Minute)
+	results, followUp, _ = store.TakeCIResults(ctx, "owner/repo@abc")
+	if len(results) != 1 || !followUp {
+		t.Fatalf("late TakeCIResults = %d results, followUp %v; want 1, true", len(results), followUp)
+	}
+}
@@ -2,7 +2,17 @@ package storage
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,15 +21,46 @@ import (
 const (
 	// ClosedPRTTL PR 關閉後保留 7 天
 	ClosedPRTTL = 7 * 24 * time.Hour
+
+	// 單次操作遇到連線錯誤時的重試次數與初始間隔（每次加倍）
+	maxRetries     = 2
+	retryBaseDelay = 100 * time.Millisecond
+
+	// 啟動時 Ping 的重試次數與初始間隔，避免 Redis 比 pod 晚起來就直接 crash
+	connectAttempts  = 5
+	connectBaseDelay = 500 * time.Millisecond
+
+	// reviewKeyPrefix 每個 PR 的 reviewer 狀態存在 hash "reviews:<prID>"，field 為 reviewer login
+	reviewKeyPrefix = "reviews:"
+
+	// statusKeyPrefix 每個 PR 狀態訊息的 message ID 存在 "status:<prID>"
+	statusKeyPrefix = "status:"
+
+	// mergeableKeyPrefix 每個 PR 最後記錄的 mergeable_state 存在 "mergeable:<prID>"
+	mergeableKeyPrefix = "mergeable:"
+
+	// 索引 key：open_prs 為未關閉 PR 的 set，closed_prs 為已關閉 PR 的 sorted set（score 為 TTL 到期時間）
+	indexKeyPrefix = "bridge:"
+	openPRsKey     = indexKeyPrefix + "open_prs"
+	closedPRsKey   = indexKeyPrefix + "closed_prs"
+	lockKeyPrefix  = indexKeyPrefix + "lock:"
+	// backfilledKey open_prs index 的 backfill 已完成的標記
+	// 沒有開啟中的 PR 時 Redis 會刪掉空的 open_prs，不能用它是否存在判斷是否已 backfill
+	backfilledKey = indexKeyPrefix + "index:backfilled"
+
+	// SCAN 每批建議回傳的 key 數量
+	scanBatchSize = 100
 )
 
 type RedisStore struct {
 	client *redis.Client
-	ctx    context.Context
+	prefix string // 加在所有 key 前面（REDIS_KEY_PREFIX），空字串表示直接寫在根目錄
 }
 
 // NewRedisStore 建立 Redis storage（接受 redis:// URL）
-func NewRedisStore(redisURL string) (*RedisStore, error) {
+// keyPrefix 加在所有 key（包含 index 與 lock）前面，例如 "bridge:<instance>:"
+// 多個 app 或多個 bridge instance 共用同一個 Redis 時必須設定不同的 prefix，否則 PR mapping 會互相覆蓋
+func NewRedisStore(redisURL, keyPrefix string) (*RedisStore, error) {
 	opts, err := redis.ParseURL(redisURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
@@ -28,29 +69,59 @@ func NewRedisStore(redisURL string) (*RedisStore, error) {
 
 	ctx := context.Background()
 
-	// 測試連線
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	// 測試連線（帶 backoff 重試）
+	var pingErr error
+	for attempt := 0; attempt < connectAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(connectBaseDelay << (attempt - 1))
+		}
+		if pingErr = client.Ping(ctx).Err(); pingErr == nil {
+			break
+		}
+	}
+	if pingErr != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis after %d attempts: %w: %w", connectAttempts, ErrStoreUnavailable, pingErr)
 	}
 
-	return &RedisStore{
+	store := &RedisStore{
 		client: client,
-		ctx:    ctx,
-	}, nil
+		prefix: keyPrefix,
+	}
+
+	if err := store.backfillOpenIndex(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return store, nil
 }
 
 // Set 儲存 PR → Thread 對應，不設定 TTL（永久保存）
-func (r *RedisStore) Set(prID, threadID string) error {
+func (r *RedisStore) Set(ctx context.Context, prID, threadID string) error {
 	// TTL = 0 表示永不過期
-	if err := r.client.Set(r.ctx, prID, threadID, 0).Err(); err != nil {
+	err := r.withRetry(ctx, func() error {
+		pipe := r.client.TxPipeline()
+		pipe.Set(ctx, r.key(prID), threadID, 0)
+		pipe.SAdd(ctx, r.key(openPRsKey), prID)
+		pipe.ZRem(ctx, r.key(closedPRsKey), prID)
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("failed to set mapping: %w", err)
 	}
 	return nil
 }
 
 // Get 取得 Thread ID
-func (r *RedisStore) Get(prID string) (string, bool, error) {
-	val, err := r.client.Get(r.ctx, prID).Result()
+func (r *RedisStore) Get(ctx context.Context, prID string) (string, bool, error) {
+	var val string
+	err := r.withRetry(ctx, func() error {
+		var getErr error
+		val, getErr = r.client.Get(ctx, r.key(prID)).Result()
+		return getErr
+	})
 
 	// Key 不存在
 	if err == redis.Nil {
@@ -66,17 +137,27 @@ func (r *RedisStore) Get(prID string) (string, bool, error) {
 }
 
 // Delete 刪除對應關係
-func (r *RedisStore) Delete(prID string) error {
-	if err := r.client.Del(r.ctx, prID).Err(); err != nil {
+func (r *RedisStore) Delete(ctx context.Context, prID string) error {
+	err := r.withRetry(ctx, func() error {
+		pipe := r.client.TxPipeline()
+		pipe.Del(ctx, r.key(prID))
+		pipe.Del(ctx, r.key(statusKeyPrefix+prID))
+		pipe.Del(ctx, r.key(mergeableKeyPrefix+prID))
+		pipe.SRem(ctx, r.key(openPRsKey), prID)
+		pipe.ZRem(ctx, r.key(closedPRsKey), prID)
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete mapping: %w", err)
 	}
 	return nil
 }
 
 // MarkAsClosed PR 關閉時呼叫，設定 7 天 TTL
-func (r *RedisStore) MarkAsClosed(prID string) error {
+func (r *RedisStore) MarkAsClosed(ctx context.Context, prID string) error {
 	// 先取得現有的 threadID
-	threadID, exists, err := r.Get(prID)
+	threadID, exists, err := r.Get(ctx, prID)
 	if err != nil {
 		return err
 	}
@@ -87,13 +168,334 @@ func (r *RedisStore) MarkAsClosed(prID string) error {
 	}
 
 	// 重新設定，帶 7 天 TTL
-	if err := r.client.Set(r.ctx, prID, threadID, ClosedPRTTL).Err(); err != nil {
+	err = r.withRetry(ctx, func() error {
+		pipe := r.client.TxPipeline()
+		pipe.Set(ctx, r.key(prID), threadID, ClosedPRTTL)
+		pipe.Expire(ctx, r.key(reviewKeyPrefix+prID), ClosedPRTTL)
+		pipe.Expire(ctx, r.key(statusKeyPrefix+prID), ClosedPRTTL)
+		pipe.Expire(ctx, r.key(mergeableKeyPrefix+prID), ClosedPRTTL)
+		pipe.SRem(ctx, r.key(openPRsKey), prID)
+		// closed_prs 以到期時間為 score，List 時清掉已到期的項目，與 key 的 TTL 保持一致
+		pipe.ZAdd(ctx, r.key(closedPRsKey), redis.Z{Score: float64(time.Now().Add(ClosedPRTTL).Unix()), Member: prID})
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("failed to mark as closed: %w", err)
 	}
 
 	return nil
 }
 
+// SetReview 記錄 reviewer 的 review 狀態（與 PR mapping 相同，PR 關閉前不設 TTL）
+func (r *RedisStore) SetReview(ctx context.Context, prID, reviewer string, record ReviewRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal review record: %w", err)
+	}
+
+	err = r.withRetry(ctx, func() error {
+		return r.client.HSet(ctx, r.key(reviewKeyPrefix+prID), reviewer, data).Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set review state: %w", err)
+	}
+	return nil
+}
+
+// GetReview 取得 reviewer 最後的 review 狀態
+func (r *RedisStore) GetReview(ctx context.Context, prID, reviewer string) (ReviewRecord, bool, error) {
+	var val string
+	err := r.withRetry(ctx, func() error {
+		var getErr error
+		val, getErr = r.client.HGet(ctx, r.key(reviewKeyPrefix+prID), reviewer).Result()
+		return getErr
+	})
+
+	if err == redis.Nil {
+		return ReviewRecord{}, false, nil
+	}
+	if err != nil {
+		return ReviewRecord{}, false, fmt.Errorf("failed to get review state: %w", err)
+	}
+
+	var record ReviewRecord
+	if err := json.Unmarshal([]byte(val), &record); err != nil {
+		return ReviewRecord{}, false, fmt.Errorf("failed to parse review state: %w", err)
+	}
+	return record, true, nil
+}
+
+// SetStatusMessage 記錄狀態訊息的 message ID
+// PR 已關閉（mapping 有 TTL）時沿用同樣的 TTL，避免留下永久的 key
+func (r *RedisStore) SetStatusMessage(ctx context.Context, prID, messageID string) error {
+	err := r.withRetry(ctx, func() error {
+		ttl, err := r.client.TTL(ctx, r.key(prID)).Result()
+		if err != nil {
+			return err
+		}
+		if ttl < 0 {
+			ttl = 0
+		}
+		return r.client.Set(ctx, r.key(statusKeyPrefix+prID), messageID, ttl).Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set status message: %w", err)
+	}
+	return nil
+}
+
+// GetStatusMessage 取得狀態訊息的 message ID
+func (r *RedisStore) GetStatusMessage(ctx context.Context, prID string) (string, bool, error) {
+	var val string
+	err := r.withRetry(ctx, func() error {
+		var getErr error
+		val, getErr = r.client.Get(ctx, r.key(statusKeyPrefix+prID)).Result()
+		return getErr
+	})
+
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get status message: %w", err)
+	}
+	return val, true, nil
+}
+
+// SwapMergeableState 以 SET ... GET 原子地寫入新的 mergeable_state 並取回舊值
+// 同一個 PR 的事件同時到達時，只有一個會看到狀態轉換
+func (r *RedisStore) SwapMergeableState(ctx context.Context, prID, state string) (string, error) {
+	var previous string
+	err := r.withRetry(ctx, func() error {
+		ttl, err := r.client.TTL(ctx, r.key(prID)).Result()
+		if err != nil {
+			return err
+		}
+		if ttl < 0 {
+			ttl = 0
+		}
+		previous, err = r.client.SetArgs(ctx, r.key(mergeableKeyPrefix+prID), state, redis.SetArgs{TTL: ttl, Get: true}).Result()
+		if err == redis.Nil {
+			previous, err = "", nil
+		}
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to swap mergeable state: %w", err)
+	}
+	return previous, nil
+}
+
+// List 回傳所有未關閉的 PR identifier（來自 open_prs index）
+// 同時清掉 closed_prs 中 TTL 已到期的項目
+func (r *RedisStore) List(ctx context.Context) ([]string, error) {
+	var prIDs []string
+	err := r.withRetry(ctx, func() error {
+		pipe := r.client.TxPipeline()
+		pipe.ZRemRangeByScore(ctx, r.key(closedPRsKey), "-inf", strconv.FormatInt(time.Now().Unix(), 10))
+		members := pipe.SMembers(ctx, r.key(openPRsKey))
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		prIDs = members.Val()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open PRs: %w", err)
+	}
+
+	return prIDs, nil
+}
+
+// TTL 回傳 key 剩餘的 TTL，沒有設定 TTL（未關閉）時回傳 0
+func (r *RedisStore) TTL(ctx context.Context, prID string) (time.Duration, bool, error) {
+	var ttl time.Duration
+	err := r.withRetry(ctx, func() error {
+		var ttlErr error
+		ttl, ttlErr = r.client.TTL(ctx, r.key(prID)).Result()
+		return ttlErr
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get TTL: %w", err)
+	}
+
+	// go-redis 以 -2 表示 key 不存在、-1 表示沒有 TTL
+	switch {
+	case ttl == -2:
+		return 0, false, nil
+	case ttl < 0:
+		return 0, true, nil
+	}
+	return ttl, true, nil
+}
+
+// unlockScript 只在 value 仍是自己的 token 時刪除，避免刪到 TTL 過期後別人重新取得的鎖
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Lock 以 SET NX PX 取得鎖，value 為隨機 token
+func (r *RedisStore) Lock(ctx context.Context, key string, ttl time.Duration) (bool, func(), error) {
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return false, nil, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	lockKey := r.key(lockKeyPrefix + key)
+
+	var acquired bool
+	err := r.withRetry(ctx, func() error {
+		var setErr error
+		acquired, setErr = r.client.SetNX(ctx, lockKey, token, ttl).Result()
+		return setErr
+	})
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if !acquired {
+		return false, func() {}, nil
+	}
+
+	// 釋放時 ctx 可能已被取消（例如請求結束），仍要嘗試刪除自己的鎖
+	unlockCtx := context.WithoutCancel(ctx)
+	var once sync.Once
+	unlock := func() {
+		once.Do(func() {
+			// 釋放失敗時鎖會在 ttl 後自動過期，不需要回報
+			_ = r.withRetry(unlockCtx, func() error {
+				return unlockScript.Run(unlockCtx, r.client, []string{lockKey}, token).Err()
+			})
+		})
+	}
+	return true, unlock, nil
+}
+
+// backfillIndexScript mapping 仍沒有 TTL（PR 開啟中）時才加入 open_prs
+// 檢查與 SADD 在同一個 script 中執行，避免 SCAN 途中 MarkAsClosed 先 SREM、之後又被加回去
+var backfillIndexScript = redis.NewScript(`
+if redis.call("TTL", KEYS[1]) == -1 then
+	return redis.call("SADD", KEYS[2], ARGV[1])
+end
+return 0
+`)
+
+// backfillOpenIndex 為升級前就存在的 mapping 建立 open_prs index
+// 以 SCAN 找出沒有 TTL 的 PR key，完成後寫入 backfilledKey，之後啟動不再執行
+func (r *RedisStore) backfillOpenIndex(ctx context.Context) error {
+	exists, err := r.client.Exists(ctx, r.key(backfilledKey)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to check open PR index: %w", err)
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	var cursor uint64
+
+	for {
+		var keys []string
+		err := r.withRetry(ctx, func() error {
+			var scanErr error
+			keys, cursor, scanErr = r.client.Scan(ctx, cursor, escapeGlob(r.prefix)+"*#*", scanBatchSize).Result()
+			return scanErr
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scan mappings: %w", err)
+		}
+
+		for _, key := range keys {
+			prID := strings.TrimPrefix(key, r.prefix)
+			if strings.HasPrefix(prID, reviewKeyPrefix) || strings.HasPrefix(prID, statusKeyPrefix) || strings.HasPrefix(prID, mergeableKeyPrefix) || strings.HasPrefix(prID, indexKeyPrefix) {
+				continue
+			}
+
+			// 已關閉（有 TTL）或已過期的 mapping 由 script 略過
+			if err := backfillIndexScript.Run(ctx, r.client, []string{key, r.key(openPRsKey)}, prID).Err(); err != nil {
+				return fmt.Errorf("failed to index %s: %w", key, err)
+			}
+		}
+
+		if cursor == 0 {
+			break
+		}
+	}
+
+	if err := r.client.Set(ctx, r.key(backfilledKey), 1, 0).Err(); err != nil {
+		return fmt.Errorf("failed to mark open PR index as backfilled: %w", err)
+	}
+
+	return nil
+}
+
+// key 回傳加上 prefix 的完整 key
+func (r *RedisStore) key(name string) string {
+	return r.prefix + name
+}
+
+// escapeGlob 跳脫 SCAN pattern 的萬用字元，讓 prefix 以字面比對
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// withRetry 對連線層級的暫時性錯誤做 backoff 重試
+// redis.Nil（key 不存在）等正常回應不重試，直接回傳給呼叫端判斷；ctx 取消時停止等待
+// 重試後仍失敗的連線錯誤包成 ErrStoreUnavailable
+func (r *RedisStore) withRetry(ctx context.Context, op func() error) error {
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(retryBaseDelay << (attempt - 1)):
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %w", ErrStoreUnavailable, err)
+			}
+		}
+		err = op()
+		if err == nil || !isTransientError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %w", ErrStoreUnavailable, err)
+}
+
+// isTransientError 判斷是否為可重試的連線錯誤
+func isTransientError(err error) bool {
+	// context.DeadlineExceeded 也實作了 net.Error，要在下面的判斷之前排除
+	if errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, redis.ErrPoolTimeout) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Ping 檢查 Redis 連線
+func (r *RedisStore) Ping(ctx context.Context) error {
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis ping failed: %w", err)
+	}
+	return nil
+}
+
 // Close 關閉 Redis 連線
 func (r *RedisStore) Close() error {
 	return r.client.Close()
@@ -1,16 +1,63 @@
 package storage
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrStoreUnavailable storage 連線層級的失敗（重試後仍連不上），與 key 不存在等正常錯誤區分
+// 可用 errors.Is 判斷，webhook 會回 503 而不是 500
+var ErrStoreUnavailable = errors.New("store unavailable")
+
+// ReviewRecord 某位 reviewer 在某個 PR 上最後一次的 review 狀態
+type ReviewRecord struct {
+	State string `json:"state"` // approved, changes_requested, commented, pending（已重新請求 review）
+	Round int    `json:"round"` // 第幾輪 review，從 1 開始
+}
+
 // Store 定義 PR → Discord Thread ID 的儲存介面
+// 所有操作都接受 ctx，取消或逾時時中止進行中的呼叫與重試
 type Store interface {
 	// Set 儲存 PR 和 Thread 的對應關係（無 TTL）
-	Set(prID, threadID string) error
+	Set(ctx context.Context, prID, threadID string) error
 
 	// Get 取得對應的 Thread ID
-	Get(prID string) (threadID string, exists bool, err error)
+	Get(ctx context.Context, prID string) (threadID string, exists bool, err error)
 
 	// Delete 刪除對應關係（少用，通常用 MarkAsClosed）
-	Delete(prID string) error
+	Delete(ctx context.Context, prID string) error
 
 	// MarkAsClosed 標記 PR 已關閉，設定 7 天 TTL
-	MarkAsClosed(prID string) error
+	MarkAsClosed(ctx context.Context, prID string) error
+
+	// SetReview 記錄 reviewer 在 PR 上的 review 狀態
+	SetReview(ctx context.Context, prID, reviewer string, record ReviewRecord) error
+
+	// GetReview 取得 reviewer 在 PR 上最後的 review 狀態
+	GetReview(ctx context.Context, prID, reviewer string) (record ReviewRecord, exists bool, err error)
+
+	// SetStatusMessage 記錄 PR thread 中狀態訊息的 message ID（跟著 PR mapping 的 TTL）
+	SetStatusMessage(ctx context.Context, prID, messageID string) error
+
+	// GetStatusMessage 取得 PR thread 中狀態訊息的 message ID
+	GetStatusMessage(ctx context.Context, prID string) (messageID string, exists bool, err error)
+
+	// SwapMergeableState 記錄 PR 最新的 mergeable_state 並回傳先前記錄的值（沒有時為空字串）
+	// 用來只在進入 / 離開 conflict 時通知；跟著 PR mapping 的 TTL
+	SwapMergeableState(ctx context.Context, prID, state string) (previous string, err error)
+
+	// List 列出所有尚未關閉的 PR identifier（reconcile、管理工具用）
+	List(ctx context.Context) ([]string, error)
+
+	// TTL 取得對應關係剩餘的存活時間（除錯用）
+	// 未關閉的 PR 沒有 TTL，回傳 0；對應關係不存在時 exists 為 false
+	TTL(ctx context.Context, prID string) (ttl time.Duration, exists bool, err error)
+
+	// Lock 取得短期的互斥鎖（多個 instance 間共用），ttl 到期自動釋放
+	// acquired 為 false 表示已被其他人持有；unlock 只釋放自己持有的鎖，可重複呼叫
+	Lock(ctx context.Context, key string, ttl time.Duration) (acquired bool, unlock func(), err error)
+
+	// Ping 檢查 storage 是否可用（health check 用，不重試）
+	Ping(ctx context.Context) error
 }
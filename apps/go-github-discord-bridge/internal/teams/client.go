@@ -0,0 +1,326 @@
+package teams
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"dizzycode1112/github-discord-bridge/internal/discord"
+	"dizzycode1112/github-discord-bridge/pkg/applogger"
+)
+
+const (
+	// TokenURL Bot Framework 的 OAuth token endpoint
+	TokenURL   = "https://login.microsoftonline.com/botframework.com/oauth2/v2.0/token"
+	TokenScope = "https://api.botframework.com/.default"
+)
+
+// Client Microsoft Teams（Bot Framework connector）client
+// Teams 沒有 thread 的概念，這裡的 "thread" 對應到 channel 中的一個 conversation（reply chain），
+// conversation ID 和 Discord thread ID 一樣存進 storage
+type Client struct {
+	serviceURL string
+	channelID  string
+	appID      string
+	appSecret  string
+	httpClient *http.Client
+	dryRun     bool
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewClient 建立 Teams client
+// serviceURL 為 Bot Framework 的 service URL（例如 https://smba.trafficmanager.net/amer/）
+func NewClient(serviceURL, channelID, appID, appSecret string) *Client {
+	return &Client{
+		serviceURL: strings.TrimSuffix(serviceURL, "/"),
+		channelID:  channelID,
+		appID:      appID,
+		appSecret:  appSecret,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// EnableDryRun 開啟 dry-run 模式：建立 conversation 與回覆只把 card 印到 log，不真的送出（同 discord.Client.EnableDryRun）
+func (c *Client) EnableDryRun() {
+	c.dryRun = true
+}
+
+// logDryRun 印出 dry-run 模式下原本要送出的 payload
+func logDryRun(action string, target string, payload any) {
+	jsonData, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		applogger.Log.Error("[dry-run] Failed to marshal payload", "action", action, "error", err)
+		return
+	}
+	applogger.Log.Info("[dry-run] Teams request skipped", "action", action, "target", target, "payload", string(jsonData))
+}
+
+// Activity Bot Framework 的訊息結構
+type Activity struct {
+	Type        string       `json:"type"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+type Attachment struct {
+	ContentType string       `json:"contentType"`
+	Content     AdaptiveCard `json:"content"`
+}
+
+// AdaptiveCard Teams 的卡片格式（對應 Discord embed）
+type AdaptiveCard struct {
+	Schema  string        `json:"$schema"`
+	Type    string        `json:"type"`
+	Version string        `json:"version"`
+	Body    []CardElement `json:"body"`
+	Actions []CardAction  `json:"actions,omitempty"`
+}
+
+type CardElement struct {
+	Type   string `json:"type"` // TextBlock, FactSet
+	Text   string `json:"text,omitempty"`
+	Size   string `json:"size,omitempty"`
+	Weight string `json:"weight,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Wrap   bool   `json:"wrap,omitempty"`
+	Facts  []Fact `json:"facts,omitempty"`
+}
+
+type Fact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type CardAction struct {
+	Type  string `json:"type"` // Action.OpenUrl
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// CreateConversationRequest 在 channel 建立新 conversation 的請求
+type CreateConversationRequest struct {
+	IsGroup     bool        `json:"isGroup"`
+	ChannelData ChannelData `json:"channelData"`
+	Activity    Activity    `json:"activity"`
+}
+
+type ChannelData struct {
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+}
+
+// CreateConversationResponse Bot Framework 的回應
+type CreateConversationResponse struct {
+	ID         string `json:"id"` // Conversation ID
+	ActivityID string `json:"activityId"`
+}
+
+// CreateThread 在 channel 建立新的 conversation，第一則訊息帶上 thread 標題
+func (c *Client) CreateThread(ctx context.Context, title string, message discord.ThreadMessage, tagIDs ...string) (string, error) {
+	reqBody := CreateConversationRequest{
+		IsGroup:  true,
+		Activity: toActivity(title, message),
+	}
+	reqBody.ChannelData.Channel.ID = c.channelID
+
+	if c.dryRun {
+		logDryRun("CreateConversation", c.channelID, reqBody)
+		return fmt.Sprintf("dry-run-%d", time.Now().UnixNano()), nil
+	}
+
+	var result CreateConversationResponse
+	if err := c.post(ctx, c.serviceURL+"/v3/conversations", reqBody, &result); err != nil {
+		return "", err
+	}
+
+	return result.ID, nil
+}
+
+// PostMessage 在已存在的 conversation 中回覆
+func (c *Client) PostMessage(ctx context.Context, threadID string, message discord.ThreadMessage) error {
+	activity := toActivity("", message)
+	if c.dryRun {
+		logDryRun("PostActivity", threadID, activity)
+		return nil
+	}
+
+	endpoint := fmt.Sprintf("%s/v3/conversations/%s/activities", c.serviceURL, url.PathEscape(threadID))
+	return c.post(ctx, endpoint, activity, nil)
+}
+
+// ArchiveThread Teams 沒有 archive conversation 的 API，這裡是 no-op
+func (c *Client) ArchiveThread(ctx context.Context, threadID string) error {
+	return nil
+}
+
+// toActivity 把 Discord 的 ThreadMessage 轉成 Adaptive Card
+func toActivity(title string, message discord.ThreadMessage) Activity {
+	card := AdaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+	}
+
+	if title != "" {
+		card.Body = append(card.Body, CardElement{Type: "TextBlock", Text: title, Size: "Large", Weight: "Bolder", Wrap: true})
+	}
+
+	if message.Content != "" {
+		card.Body = append(card.Body, CardElement{Type: "TextBlock", Text: message.Content, Wrap: true})
+	}
+
+	for _, embed := range message.Embeds {
+		if embed.Title != "" {
+			card.Body = append(card.Body, CardElement{Type: "TextBlock", Text: embed.Title, Size: "Medium", Weight: "Bolder", Color: toCardColor(embed.Color), Wrap: true})
+		}
+		if embed.Description != "" {
+			card.Body = append(card.Body, CardElement{Type: "TextBlock", Text: embed.Description, Wrap: true})
+		}
+		if len(embed.Fields) > 0 {
+			facts := make([]Fact, 0, len(embed.Fields))
+			for _, f := range embed.Fields {
+				facts = append(facts, Fact{Title: f.Name, Value: f.Value})
+			}
+			card.Body = append(card.Body, CardElement{Type: "FactSet", Facts: facts})
+		}
+		if embed.URL != "" {
+			card.Actions = append(card.Actions, CardAction{Type: "Action.OpenUrl", Title: "Open in GitHub", URL: embed.URL})
+		}
+	}
+
+	// Discord 連結按鈕對應到 Action.OpenUrl
+	for _, row := range message.Components {
+		for _, b := range row.Components {
+			if b.Style == discord.ButtonStyleLink && b.URL != "" {
+				card.Actions = append(card.Actions, CardAction{Type: "Action.OpenUrl", Title: b.Label, URL: b.URL})
+			}
+		}
+	}
+
+	return Activity{
+		Type: "message",
+		Attachments: []Attachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content:     card,
+			},
+		},
+	}
+}
+
+// toCardColor Adaptive Card 只支援固定的語意顏色，依目前 theme 的 Discord 顏色對應
+func toCardColor(color int) string {
+	theme := discord.CurrentTheme()
+	switch color {
+	case theme.Opened, theme.CIPass:
+		return "Good"
+	case theme.Closed, theme.CIFail:
+		return "Attention"
+	case theme.Review:
+		return "Warning"
+	case theme.Merged:
+		return "Accent"
+	default:
+		return "Default"
+	}
+}
+
+// post 發送 POST 請求，out 不為 nil 時解析 JSON 回應；ctx 取消時中止請求
+func (c *Client) post(ctx context.Context, endpoint string, payload any, out any) error {
+	token, err := c.getAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("teams API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// getAccessToken 用 client credentials 取得 Bot Framework token，過期前重用
+func (c *Client) getAccessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && time.Now().Before(c.expiresAt) {
+		return c.accessToken, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.appID},
+		"client_secret": {c.appSecret},
+		"scope":         {TokenScope},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("teams token error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	c.accessToken = token.AccessToken
+	// 提早一分鐘視為過期，避免邊界情況
+	c.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+
+	return c.accessToken, nil
+}
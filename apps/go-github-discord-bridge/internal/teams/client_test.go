@@ -0,0 +1,45 @@
+package teams
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"dizzycode1112/github-discord-bridge/internal/discord"
+	"dizzycode1112/github-discord-bridge/pkg/applogger"
+)
+
+func TestClientDryRunSendsNothing(t *testing.T) {
+	applogger.Init("test")
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "channel-1", "app-id", "app-secret")
+	client.EnableDryRun()
+	ctx := context.Background()
+
+	first, err := client.CreateThread(ctx, "PR #1", discord.ThreadMessage{Content: "hello"})
+	if err != nil {
+		t.Fatalf("CreateThread: %v", err)
+	}
+	second, err := client.CreateThread(ctx, "PR #2", discord.ThreadMessage{Content: "hello"})
+	if err != nil {
+		t.Fatalf("CreateThread: %v", err)
+	}
+	if first == "" || first == second {
+		t.Fatalf("dry-run conversation IDs = %q, %q; want unique non-empty IDs", first, second)
+	}
+
+	if err := client.PostMessage(ctx, first, discord.ThreadMessage{Content: "hello"}); err != nil {
+		t.Fatalf("PostMessage: %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Fatalf("dry-run client sent %d requests", requests.Load())
+	}
+}
@@ -0,0 +1,173 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultConnectTimeout is how long NewClientConn waits for the connection to become ready
+const DefaultConnectTimeout = 10 * time.Second
+
+// DefaultClientKeepalive pings idle connections every minute, which stays within the
+// server's DefaultKeepaliveEnforcement so the server never closes the connection for pinging too often
+var DefaultClientKeepalive = keepalive.ClientParameters{
+	Time:                time.Minute,
+	Timeout:             20 * time.Second,
+	PermitWithoutStream: true,
+}
+
+// DefaultRetryPolicy retries Unavailable and DeadlineExceeded up to 3 attempts in total
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Codes:          []codes.Code{codes.Unavailable, codes.DeadlineExceeded},
+}
+
+// RetryPolicy controls the unary retry interceptor
+type RetryPolicy struct {
+	MaxAttempts    int           // total attempts including the first call
+	InitialBackoff time.Duration // wait before the first retry, doubled after each attempt
+	MaxBackoff     time.Duration // upper bound for the wait between attempts
+	Codes          []codes.Code  // status codes that are retried
+}
+
+type ClientOptions struct {
+	TLS *tls.Config // optional, plaintext if nil
+
+	// Keepalive optional, uses DefaultClientKeepalive if nil
+	Keepalive *keepalive.ClientParameters
+
+	// ConnectTimeout bounds the wait for the connection to become ready;
+	// optional, uses DefaultConnectTimeout if zero, negative connects lazily on the first call
+	ConnectTimeout time.Duration
+
+	// Retry retries failed unary calls; optional, no retries if nil.
+	// Only enable it for connections whose unary methods are all idempotent.
+	Retry *RetryPolicy
+
+	Log Logger // optional, logs every unary call (method, code, duration) if set
+
+	DialOptions []grpc.DialOption // optional, appended after the options above
+}
+
+// NewClientConn creates a client connection to target with the package defaults
+// and waits up to ConnectTimeout for it to become ready
+func NewClientConn(target string, opts ClientOptions) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if opts.TLS != nil {
+		creds = credentials.NewTLS(opts.TLS)
+	}
+
+	params := DefaultClientKeepalive
+	if opts.Keepalive != nil {
+		params = *opts.Keepalive
+	}
+
+	var interceptors []grpc.UnaryClientInterceptor
+	if opts.Log != nil {
+		interceptors = append(interceptors, loggingInterceptor(opts.Log))
+	}
+	if opts.Retry != nil {
+		interceptors = append(interceptors, retryInterceptor(*opts.Retry))
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithKeepaliveParams(params),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+	}
+	dialOpts = append(dialOpts, opts.DialOptions...)
+
+	conn, err := grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	timeout := opts.ConnectTimeout
+	if timeout == 0 {
+		timeout = DefaultConnectTimeout
+	}
+	if timeout < 0 {
+		return conn, nil
+	}
+
+	if err := waitForReady(conn, timeout); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to %s: %w", target, err)
+	}
+
+	return conn, nil
+}
+
+// waitForReady starts connecting and blocks until conn is Ready or timeout passes
+func waitForReady(conn *grpc.ClientConn, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("not ready after %s (last state %s)", timeout, state)
+		}
+	}
+}
+
+// retryInterceptor retries calls that fail with one of policy.Codes, with exponential backoff
+func retryInterceptor(policy RetryPolicy) grpc.UnaryClientInterceptor {
+	retryable := make(map[codes.Code]bool, len(policy.Codes))
+	for _, code := range policy.Codes {
+		retryable[code] = true
+	}
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		backoff := policy.InitialBackoff
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, callOpts...)
+			if err == nil || attempt >= policy.MaxAttempts || !retryable[status.Code(err)] {
+				return err
+			}
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+
+			backoff *= 2
+			if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+				backoff = policy.MaxBackoff
+			}
+		}
+	}
+}
+
+// loggingInterceptor logs the method, status code and duration of every unary call
+func loggingInterceptor(log Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		log.Info("gRPC call", map[string]any{
+			"method":   method,
+			"code":     status.Code(err).String(),
+			"duration": time.Since(start).String(),
+		})
+		return err
+	}
+}
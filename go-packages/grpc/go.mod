@@ -2,9 +2,17 @@ module dizzycoder1112/Dockerize-Monorepo-Structure-In-Node-And-Golang/grpc
 
 go 1.25.1
 
-require google.golang.org/grpc v1.75.1
+require (
+	github.com/prometheus/client_golang v1.19.1
+	google.golang.org/grpc v1.75.1
+)
 
 require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/prometheus/client_model v0.5.0 // indirect
+	github.com/prometheus/common v0.48.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
 	golang.org/x/net v0.44.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
@@ -0,0 +1,156 @@
+package grpc
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsRecorder receives one call per finished RPC (unary or streaming).
+// Set ServerDeps.Metrics to opt in; LogMetrics and promstats.PrometheusMetrics implement it.
+type MetricsRecorder interface {
+	RecordRPC(method string, code codes.Code, dur time.Duration)
+}
+
+// metricsInterceptors returns the unary and stream interceptors that report to m
+func metricsInterceptors(m MetricsRecorder) []grpc.ServerOption {
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.RecordRPC(info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.RecordRPC(info.FullMethod, status.Code(err), time.Since(start))
+		return err
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary),
+		grpc.ChainStreamInterceptor(stream),
+	}
+}
+
+// DefaultLogMetricsInterval is how often LogMetrics logs a summary when no positive interval is given
+const DefaultLogMetricsInterval = time.Minute
+
+// methodStats aggregated counters for one method within a LogMetrics interval
+type methodStats struct {
+	requests int
+	errors   map[codes.Code]int
+	total    time.Duration
+	max      time.Duration
+}
+
+// LogMetrics aggregates RPC metrics in memory and logs a summary per method
+// through the Logger every interval. Call Close to stop it and log the last interval.
+type LogMetrics struct {
+	log Logger
+
+	mu      sync.Mutex
+	methods map[string]*methodStats
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewLogMetrics creates a LogMetrics that logs a summary every interval;
+// uses DefaultLogMetricsInterval if interval is zero or negative
+func NewLogMetrics(log Logger, interval time.Duration) *LogMetrics {
+	if log == nil {
+		log = &defaultLogger{}
+	}
+	if interval <= 0 {
+		interval = DefaultLogMetricsInterval
+	}
+
+	m := &LogMetrics{
+		log:     log,
+		methods: make(map[string]*methodStats),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.Flush()
+			case <-m.stop:
+				m.Flush()
+				return
+			}
+		}
+	}()
+
+	return m
+}
+
+// RecordRPC implements MetricsRecorder
+func (m *LogMetrics) RecordRPC(method string, code codes.Code, dur time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.methods[method]
+	if !ok {
+		s = &methodStats{errors: make(map[codes.Code]int)}
+		m.methods[method] = s
+	}
+	s.requests++
+	if code != codes.OK {
+		s.errors[code]++
+	}
+	s.total += dur
+	if dur > s.max {
+		s.max = dur
+	}
+}
+
+// Flush logs the summary of every method seen since the last flush and resets the counters
+func (m *LogMetrics) Flush() {
+	m.mu.Lock()
+	methods := m.methods
+	m.methods = make(map[string]*methodStats)
+	m.mu.Unlock()
+
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := methods[name]
+		errors := make(map[string]int, len(s.errors))
+		for code, count := range s.errors {
+			errors[code.String()] = count
+		}
+		m.log.Info("gRPC method stats", map[string]any{
+			"method":   name,
+			"requests": s.requests,
+			"errors":   errors,
+			"avg":      (s.total / time.Duration(s.requests)).String(),
+			"max":      s.max.String(),
+		})
+	}
+}
+
+// Close stops the periodic logging after logging the last interval
+func (m *LogMetrics) Close() {
+	m.once.Do(func() {
+		close(m.stop)
+	})
+	<-m.done
+}
@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// recordingLogger keeps every Info call so tests can inspect the summaries
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []map[string]any
+}
+
+func (l *recordingLogger) Info(msg string, context ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(context) == 1 {
+		if fields, ok := context[0].(map[string]any); ok {
+			l.entries = append(l.entries, fields)
+		}
+	}
+}
+
+func TestNewLogMetricsDefaultsNonPositiveInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		// time.NewTicker panics on a non-positive interval
+		m := NewLogMetrics(&recordingLogger{}, interval)
+		m.Close()
+	}
+}
+
+func TestLogMetricsCloseLogsLastInterval(t *testing.T) {
+	log := &recordingLogger{}
+	m := NewLogMetrics(log, time.Hour)
+
+	m.RecordRPC("/pkg.Service/Get", codes.OK, 10*time.Millisecond)
+	m.RecordRPC("/pkg.Service/Get", codes.NotFound, 30*time.Millisecond)
+	m.Close()
+
+	if len(log.entries) != 1 {
+		t.Fatalf("logged %d summaries, want 1", len(log.entries))
+	}
+	fields := log.entries[0]
+	if fields["method"] != "/pkg.Service/Get" || fields["requests"] != 2 {
+		t.Fatalf("summary = %v, want 2 requests for /pkg.Service/Get", fields)
+	}
+	if errors, _ := fields["errors"].(map[string]int); errors["NotFound"] != 1 {
+		t.Fatalf("errors = %v, want NotFound: 1", fields["errors"])
+	}
+	if fields["avg"] != "20ms" || fields["max"] != "30ms" {
+		t.Fatalf("avg/max = %v/%v, want 20ms/30ms", fields["avg"], fields["max"])
+	}
+}
@@ -0,0 +1,63 @@
+// Package promstats provides a Prometheus implementation of the grpc
+// ServerDeps.Metrics recorder. It lives in its own package so the core
+// server does not import Prometheus.
+package promstats
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+)
+
+// PrometheusMetrics records RPC counts, errors by status code and handler latency per method
+type PrometheusMetrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+}
+
+// NewPrometheusMetrics creates the collectors and registers them with reg.
+// Pass prometheus.DefaultRegisterer to expose them on the default /metrics handler.
+func NewPrometheusMetrics(reg prometheus.Registerer, namespace string) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc_server",
+			Name:      "requests_total",
+			Help:      "RPCs handled by the server, including failures.",
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc_server",
+			Name:      "errors_total",
+			Help:      "RPCs that finished with a non-OK status code.",
+		}, []string{"method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc_server",
+			Name:      "handling_seconds",
+			Help:      "Time spent handling the RPC (whole stream for streaming RPCs).",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.requests, m.errors, m.latency} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// RecordRPC implements grpc.MetricsRecorder:
+//
+//	deps.Metrics = metrics
+func (m *PrometheusMetrics) RecordRPC(method string, code codes.Code, dur time.Duration) {
+	m.requests.WithLabelValues(method).Inc()
+	if code != codes.OK {
+		m.errors.WithLabelValues(method, code.String()).Inc()
+	}
+	m.latency.WithLabelValues(method).Observe(dur.Seconds())
+}
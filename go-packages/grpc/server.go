@@ -7,8 +7,10 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -24,13 +26,50 @@ func (d *defaultLogger) Info(msg string, context ...any) {
 	log.Println(append([]any{msg}, context...)...)
 }
 
+// DefaultStopTimeout is how long GracefulStop waits for in-flight RPCs before forcing a stop
+const DefaultStopTimeout = 30 * time.Second
+
+// Default connection limits, used when the matching ServerDeps field is zero
+const (
+	DefaultMaxRecvMsgSize       = 4 << 20 // 4MB, same as grpc-go's receive default
+	DefaultMaxSendMsgSize       = 4 << 20 // 4MB
+	DefaultMaxConcurrentStreams = 1000    // per client connection
+)
+
+// DefaultKeepalive closes idle connections and pings quiet clients so dead peers are detected
+var DefaultKeepalive = keepalive.ServerParameters{
+	MaxConnectionIdle: 15 * time.Minute,
+	Time:              5 * time.Minute,
+	Timeout:           20 * time.Second,
+}
+
+// DefaultKeepaliveEnforcement rejects clients that ping more often than every 30 seconds
+var DefaultKeepaliveEnforcement = keepalive.EnforcementPolicy{
+	MinTime:             30 * time.Second,
+	PermitWithoutStream: true,
+}
+
 type Server struct {
-	grpcServer *grpc.Server
-	log        Logger
+	grpcServer  *grpc.Server
+	log         Logger
+	stopTimeout time.Duration
 }
 
 type ServerDeps struct {
 	Log Logger // optional, uses default if nil
+
+	// StopTimeout bounds GracefulStop; optional, uses DefaultStopTimeout if zero, negative waits indefinitely
+	StopTimeout time.Duration
+
+	// Keepalive and connection limits; optional, each uses its Default* value if nil or zero
+	Keepalive            *keepalive.ServerParameters
+	KeepaliveEnforcement *keepalive.EnforcementPolicy
+	MaxConcurrentStreams uint32
+	MaxRecvMsgSize       int
+	MaxSendMsgSize       int
+
+	// Metrics records per-method counts, error codes and latency; optional, no interceptor if nil
+	Metrics MetricsRecorder
 }
 
 func NewServer(deps *ServerDeps) *Server {
@@ -39,10 +78,55 @@ func NewServer(deps *ServerDeps) *Server {
 		logger = deps.Log
 	}
 
+	stopTimeout := DefaultStopTimeout
+	if deps != nil && deps.StopTimeout != 0 {
+		stopTimeout = deps.StopTimeout
+	}
+
 	return &Server{
-		grpcServer: grpc.NewServer(),
-		log:        logger,
+		grpcServer:  grpc.NewServer(serverOptions(deps)...),
+		log:         logger,
+		stopTimeout: stopTimeout,
+	}
+}
+
+// serverOptions builds the keepalive and limit options from deps, filling in defaults
+func serverOptions(deps *ServerDeps) []grpc.ServerOption {
+	params := DefaultKeepalive
+	enforcement := DefaultKeepaliveEnforcement
+	maxStreams := uint32(DefaultMaxConcurrentStreams)
+	maxRecv := DefaultMaxRecvMsgSize
+	maxSend := DefaultMaxSendMsgSize
+
+	if deps != nil {
+		if deps.Keepalive != nil {
+			params = *deps.Keepalive
+		}
+		if deps.KeepaliveEnforcement != nil {
+			enforcement = *deps.KeepaliveEnforcement
+		}
+		if deps.MaxConcurrentStreams != 0 {
+			maxStreams = deps.MaxConcurrentStreams
+		}
+		if deps.MaxRecvMsgSize != 0 {
+			maxRecv = deps.MaxRecvMsgSize
+		}
+		if deps.MaxSendMsgSize != 0 {
+			maxSend = deps.MaxSendMsgSize
+		}
 	}
+
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(params),
+		grpc.KeepaliveEnforcementPolicy(enforcement),
+		grpc.MaxConcurrentStreams(maxStreams),
+		grpc.MaxRecvMsgSize(maxRecv),
+		grpc.MaxSendMsgSize(maxSend),
+	}
+	if deps != nil && deps.Metrics != nil {
+		opts = append(opts, metricsInterceptors(deps.Metrics)...)
+	}
+	return opts
 }
 
 // GrpcServer exposes the underlying grpc.Server for service registration
@@ -73,13 +157,41 @@ func (s *Server) start(port string, ready chan<- struct{}) error {
 	return nil
 }
 
+// GracefulStop stops the server gracefully, forcing a stop after the configured StopTimeout
 func (s *Server) GracefulStop() {
-	s.grpcServer.GracefulStop()
-	s.log.Info("gRPC server stopped")
+	if s.stopTimeout < 0 {
+		s.grpcServer.GracefulStop()
+		s.log.Info("gRPC server stopped")
+		return
+	}
+	s.GracefulStopTimeout(s.stopTimeout)
+}
+
+// GracefulStopTimeout waits up to d for in-flight RPCs to finish, then closes all
+// connections with Stop so a hung RPC (e.g. a stream that never ends) cannot block shutdown
+func (s *Server) GracefulStopTimeout(d time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		s.log.Info("gRPC server stopped")
+	case <-timer.C:
+		s.grpcServer.Stop()
+		<-done
+		s.log.Info("gRPC server forced to stop after timeout", map[string]any{"timeout": d.String()})
+	}
 }
 
 // Run starts the server in a goroutine and blocks until shutdown signal is received.
-// Returns control to the caller for cleanup. Caller should call GracefulStop().
+// Returns control to the caller for cleanup. Caller should call GracefulStop(),
+// which forces a stop after ServerDeps.StopTimeout (DefaultStopTimeout if unset).
 func (s *Server) Run(port string) error {
 	ready := make(chan struct{})
 	errChan := make(chan error, 1)
@@ -1,5 +1,13 @@
 package logger
 
+import "context"
+
+// Shutdowner is implemented by strategies that can stop within a deadline
+// (e.g. Slack, whose in-flight requests may hang)
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
 // MultiLogger dispatches log calls to multiple logger strategies
 // Similar to TypeScript's createLogger function, it allows logging to multiple destinations
 // simultaneously (e.g., console + Sentry + ELK)
@@ -56,3 +64,21 @@ func (m *MultiLogger) Flush() error {
 	}
 	return firstErr
 }
+
+// Shutdown stops every strategy within ctx: strategies implementing Shutdowner
+// are shut down, the others are flushed. Returns the first error encountered.
+func (m *MultiLogger) Shutdown(ctx context.Context) error {
+	var firstErr error
+	for _, strategy := range m.strategies {
+		var err error
+		if s, ok := strategy.(Shutdowner); ok {
+			err = s.Shutdown(ctx)
+		} else {
+			err = strategy.Flush()
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
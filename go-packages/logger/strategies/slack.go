@@ -2,11 +2,13 @@ package strategies
 
 import (
 	"bytes"
+	"context"
 	"dizzycoder1112/logger"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -56,8 +58,23 @@ type SlackOptions struct {
 	ServiceName    string
 	Environment    string
 	FaultTolerance FaultToleranceStrategy // Optional: circuit breaker or rate limiter
+	Timeout        time.Duration          // Per-request timeout (default: DefaultSlackTimeout)
+	MaxAttempts    int                    // Total attempts per message, including the first (default: DefaultSlackMaxAttempts)
+	RetryBaseDelay time.Duration          // Delay before the first retry, doubled on each retry (default: DefaultSlackRetryBaseDelay)
 }
 
+const (
+	// DefaultSlackTimeout bounds a single Slack webhook request
+	DefaultSlackTimeout = 5 * time.Second
+	// DefaultSlackMaxAttempts is the number of attempts for network errors, 5xx and 429
+	DefaultSlackMaxAttempts = 3
+	// DefaultSlackRetryBaseDelay is the delay before the first retry
+	DefaultSlackRetryBaseDelay = 500 * time.Millisecond
+
+	// maxSlackRetryAfter caps a Retry-After from Slack so Flush is not held for minutes
+	maxSlackRetryAfter = 30 * time.Second
+)
+
 // SlackStrategy sends error and warning logs to Slack
 // Only logs with level "error" or "warn" are sent to Slack
 type SlackStrategy struct {
@@ -65,18 +82,49 @@ type SlackStrategy struct {
 	serviceName    string
 	environment    string
 	faultTolerance FaultToleranceStrategy
+	httpClient     *http.Client
+	maxAttempts    int
+	retryBaseDelay time.Duration
 
 	// Pending request tracking for graceful shutdown
 	wg sync.WaitGroup
+
+	// ctx is cancelled by Shutdown to abort requests still in flight at the deadline
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// mu makes the shutdown check and wg.Add atomic with respect to Shutdown,
+	// so no Add can run concurrently with the wg.Wait that drains requests
+	mu       sync.Mutex
+	shutdown bool
 }
 
 // NewSlack creates a new Slack logger strategy
 func NewSlack(opts SlackOptions) logger.Logger {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSlackTimeout
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultSlackMaxAttempts
+	}
+	retryBaseDelay := opts.RetryBaseDelay
+	if retryBaseDelay <= 0 {
+		retryBaseDelay = DefaultSlackRetryBaseDelay
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	return &SlackStrategy{
 		webhookURL:     opts.WebhookURL,
 		serviceName:    opts.ServiceName,
 		environment:    opts.Environment,
 		faultTolerance: opts.FaultTolerance,
+		httpClient:     &http.Client{Timeout: timeout},
+		maxAttempts:    maxAttempts,
+		retryBaseDelay: retryBaseDelay,
+		ctx:            ctx,
+		cancel:         cancel,
 	}
 }
 
@@ -130,24 +178,58 @@ func (s *SlackStrategy) Error(msg string, context ...any) {
 }
 
 // Flush waits for all pending Slack requests to complete
+// Each request is bounded by the client timeout, so Flush cannot block forever
 func (s *SlackStrategy) Flush() error {
 	s.wg.Wait()
 	return nil
 }
 
+// Shutdown stops accepting new messages and waits for in-flight requests until
+// ctx is done. Requests still running at the deadline are cancelled and
+// ctx.Err() is returned without waiting for them to unwind.
+func (s *SlackStrategy) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shutdown = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
+
 func (s *SlackStrategy) sendToSlack(level string, msg string, context []any) {
 	// Skip if webhook URL is not configured
 	if s.webhookURL == "" {
 		return
 	}
 
+	s.mu.Lock()
+	// Drop messages logged after Shutdown
+	if s.shutdown {
+		s.mu.Unlock()
+		return
+	}
+
 	// Check fault tolerance before sending (if provided)
 	if s.faultTolerance != nil && !s.faultTolerance.CanExecute() {
+		s.mu.Unlock()
 		return
 	}
 
 	// Track pending request
 	s.wg.Add(1)
+	s.mu.Unlock()
 
 	go func() {
 		defer s.wg.Done()
@@ -164,29 +246,88 @@ func (s *SlackStrategy) sendToSlack(level string, msg string, context []any) {
 			return
 		}
 
-		resp, err := http.Post(s.webhookURL, "application/json", bytes.NewBuffer(jsonBytes))
-		if err != nil {
+		// Fault tolerance sees one outcome per message, after retries are exhausted
+		if err := s.deliver(jsonBytes); err != nil {
 			fmt.Fprintf(os.Stderr, "[SlackStrategy] Failed to send message: %v\n", err)
-			if s.faultTolerance != nil {
+			// A request aborted by Shutdown says nothing about Slack's health
+			if s.faultTolerance != nil && s.ctx.Err() == nil {
 				s.faultTolerance.OnFailure()
 			}
 			return
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusOK {
-			if s.faultTolerance != nil {
-				s.faultTolerance.OnSuccess()
-			}
-		} else {
-			fmt.Fprintf(os.Stderr, "[SlackStrategy] HTTP error: %d\n", resp.StatusCode)
-			if s.faultTolerance != nil {
-				s.faultTolerance.OnFailure()
-			}
+		if s.faultTolerance != nil {
+			s.faultTolerance.OnSuccess()
 		}
 	}()
 }
 
+// deliver posts the payload, retrying network errors, 5xx and 429 with
+// exponential backoff (or Slack's Retry-After) up to maxAttempts.
+// Shutdown interrupts the wait between attempts.
+func (s *SlackStrategy) deliver(body []byte) error {
+	delay := s.retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		retryAfter, retryable, err := s.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= s.maxAttempts {
+			return fmt.Errorf("%w (attempt %d/%d)", err, attempt, s.maxAttempts)
+		}
+
+		wait := delay
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		select {
+		case <-time.After(wait):
+		case <-s.ctx.Done():
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// post sends a single request. retryable reports whether another attempt may
+// succeed; retryAfter is Slack's requested wait for 429 responses.
+func (s *SlackStrategy) post(body []byte) (retryAfter time.Duration, retryable bool, err error) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, s.ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return 0, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return parseRetryAfter(resp.Header.Get("Retry-After")), true, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	case resp.StatusCode >= 500:
+		return 0, true, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	default:
+		return 0, false, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+}
+
+// parseRetryAfter reads a Retry-After header in seconds, capped at maxSlackRetryAfter
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	d := time.Duration(seconds) * time.Second
+	if d > maxSlackRetryAfter {
+		return maxSlackRetryAfter
+	}
+	return d
+}
+
 func (s *SlackStrategy) buildAttachment(level string, msg string, context []any) slackAttachment {
 	var color, emoji string
 	if level == "error" {
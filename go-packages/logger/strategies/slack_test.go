@@ -0,0 +1,57 @@
+package strategies
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSlackShutdownDrainsConcurrentMessages(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer server.Close()
+
+	s := NewSlack(SlackOptions{WebhookURL: server.URL, ServiceName: "test"}).(*SlackStrategy)
+
+	// Keep logging while Shutdown runs: every accepted message must be delivered before it returns
+	stop := make(chan struct{})
+	var loggers sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		loggers.Add(1)
+		go func() {
+			defer loggers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					s.Error("boom")
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	drained := received.Load()
+
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	loggers.Wait()
+
+	if drained == 0 {
+		t.Fatal("no message was delivered before Shutdown")
+	}
+	if got := received.Load(); got != drained {
+		t.Fatalf("%d messages were delivered after Shutdown returned", got-drained)
+	}
+}
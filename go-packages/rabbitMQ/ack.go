@@ -0,0 +1,144 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Action is the acknowledgement decision for a consumed message
+type Action int
+
+const (
+	// ActionDefault leaves the decision to the handler's returned error:
+	// nil acks, an error retries (or discards when no retry is possible)
+	ActionDefault Action = iota
+	// ActionAck acknowledges the message
+	ActionAck
+	// ActionRetry hands the message to the RetryStrategy; without one (or once
+	// retries are exhausted) the message is discarded
+	ActionRetry
+	// ActionDiscard nacks without requeue, so the message goes to the DLQ if one is configured
+	ActionDiscard
+	// ActionRequeue nacks with requeue, returning the message to the head of the queue immediately
+	ActionRequeue
+	// ActionSettled means the handler already acked or nacked the delivery
+	// itself (e.g. with SendToDLQ), so the consumer leaves it alone
+	ActionSettled
+)
+
+// String returns the action name used in logs
+func (a Action) String() string {
+	switch a {
+	case ActionAck:
+		return "ack"
+	case ActionRetry:
+		return "retry"
+	case ActionDiscard:
+		return "discard"
+	case ActionRequeue:
+		return "requeue"
+	case ActionSettled:
+		return "settled"
+	default:
+		return "default"
+	}
+}
+
+// Acknowledger lets an AckHandler decide explicitly what happens to a message.
+// The last call wins; if no method is called the returned error decides.
+// It is only valid for the duration of the handler call.
+type Acknowledger struct {
+	action Action
+}
+
+// Ack acknowledges the message, even if the handler returns an error
+func (a *Acknowledger) Ack() { a.action = ActionAck }
+
+// Retry applies the consumer's RetryStrategy, even if the handler returns nil
+func (a *Acknowledger) Retry() { a.action = ActionRetry }
+
+// Discard nacks the message without requeue and without retrying
+func (a *Acknowledger) Discard() { a.action = ActionDiscard }
+
+// RequeueNow nacks the message with requeue, bypassing the RetryStrategy delay
+func (a *Acknowledger) RequeueNow() { a.action = ActionRequeue }
+
+// Settled tells the consumer the handler has settled the delivery itself
+// (e.g. with SendToDLQ); settling it twice would close the channel
+func (a *Acknowledger) Settled() { a.action = ActionSettled }
+
+// Action returns the decision made so far
+func (a *Acknowledger) Action() Action { return a.action }
+
+// AckHandler is a MessageHandler that can make per-message ack decisions
+type AckHandler func(payload []byte, delivery amqp.Delivery, ack *Acknowledger) error
+
+// ContextHandler is a MessageHandler that receives a context, cancelled when
+// ConsumeOptions.HandlerTimeout expires, so long-running work can stop early
+type ContextHandler func(ctx context.Context, payload []byte, delivery amqp.Delivery) error
+
+// consumeHandler is what the delivery loop calls; every public handler type adapts to it
+type consumeHandler func(ctx context.Context, payload []byte, delivery amqp.Delivery, ack *Acknowledger) error
+
+// consumeHandler adapts a MessageHandler; its error alone decides the outcome
+func (h MessageHandler) consumeHandler() consumeHandler {
+	return func(_ context.Context, payload []byte, delivery amqp.Delivery, _ *Acknowledger) error {
+		return h(payload, delivery)
+	}
+}
+
+// consumeHandler adapts an AckHandler
+func (h AckHandler) consumeHandler() consumeHandler {
+	return func(_ context.Context, payload []byte, delivery amqp.Delivery, ack *Acknowledger) error {
+		return h(payload, delivery, ack)
+	}
+}
+
+// consumeHandler adapts a ContextHandler; its error alone decides the outcome
+func (h ContextHandler) consumeHandler() consumeHandler {
+	return func(ctx context.Context, payload []byte, delivery amqp.Delivery, _ *Acknowledger) error {
+		return h(ctx, payload, delivery)
+	}
+}
+
+// resolveAction turns the handler's decision and returned error into the action to apply
+func resolveAction(decided Action, err error) Action {
+	if decided != ActionDefault {
+		return decided
+	}
+	if err == nil {
+		return ActionAck
+	}
+	// Poison messages will never succeed, skip retries and dead-letter them directly
+	if errors.Is(err, ErrPoison) {
+		return ActionDiscard
+	}
+	return ActionRetry
+}
+
+// ConsumeQueueWithAck is ConsumeQueue for handlers that make explicit ack decisions
+// through an Acknowledger. Options behave exactly as in ConsumeQueue.
+func ConsumeQueueWithAck(
+	conn *Connection,
+	queue string,
+	handler AckHandler,
+	options *ConsumeOptions,
+) error {
+	_, err := consumeQueue(conn, queue, handler.consumeHandler(), options)
+	return err
+}
+
+// ConsumeQueueWithContextHandler is ConsumeQueue for handlers that take a
+// context. With options.HandlerTimeout set, the context is cancelled when the
+// timeout expires. Options behave exactly as in ConsumeQueue.
+func ConsumeQueueWithContextHandler(
+	conn *Connection,
+	queue string,
+	handler ContextHandler,
+	options *ConsumeOptions,
+) error {
+	_, err := consumeQueue(conn, queue, handler.consumeHandler(), options)
+	return err
+}
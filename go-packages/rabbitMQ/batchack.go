@@ -0,0 +1,86 @@
+package rabbitmq
+
+import (
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// DefaultBatchAckSize is the number of acks accumulated before a flush
+	DefaultBatchAckSize = 100
+	// DefaultBatchAckInterval is the longest an ack is held before a flush
+	DefaultBatchAckInterval = time.Second
+)
+
+// BatchAckOptions enables bulk acknowledgement: successfully handled messages
+// are acked together with a single multiple=true ack once Size acks are
+// pending or Interval has passed, instead of one ack per message.
+//
+// Delivery stays at-least-once, but the window grows: if the channel or the
+// process dies before a flush, up to Size already-handled messages are
+// redelivered, so handlers must be idempotent.
+//
+// A multiple ack covers every unacked delivery on the channel, so the consumer
+// must have a channel of its own (a unique ChannelID); starting it on the
+// default channel fails with ErrBatchAckSharedChannel. The channel's prefetch
+// should be larger than Size so the batch can fill up.
+type BatchAckOptions struct {
+	Size     int           // Acks per flush (DefaultBatchAckSize when <= 0)
+	Interval time.Duration // Max time an ack is held (DefaultBatchAckInterval when <= 0)
+}
+
+// ackBatcher accumulates delivery tags for one delivery loop.
+// It is only used from that loop's goroutine.
+type ackBatcher struct {
+	size     int
+	interval time.Duration
+
+	acker   amqp.Acknowledger
+	lastTag uint64
+	pending int
+}
+
+// newAckBatcher returns nil when batch acking is disabled (or pointless with NoAck)
+func newAckBatcher(options *ConsumeOptions) *ackBatcher {
+	if options.BatchAck == nil || options.NoAck {
+		return nil
+	}
+
+	b := &ackBatcher{
+		size:     options.BatchAck.Size,
+		interval: options.BatchAck.Interval,
+	}
+	if b.size <= 0 {
+		b.size = DefaultBatchAckSize
+	}
+	if b.interval <= 0 {
+		b.interval = DefaultBatchAckInterval
+	}
+	return b
+}
+
+// add records a successfully handled delivery and flushes once the batch is full
+func (b *ackBatcher) add(delivery amqp.Delivery) error {
+	b.acker = delivery.Acknowledger
+	b.lastTag = delivery.DeliveryTag
+	b.pending++
+
+	if b.pending >= b.size {
+		return b.flush()
+	}
+	return nil
+}
+
+// flush acks every pending delivery up to the last recorded tag.
+// It must run before a delivery is settled any other way, so a multiple ack
+// never reaches past a failed message.
+func (b *ackBatcher) flush() error {
+	if b.pending == 0 {
+		return nil
+	}
+
+	err := b.acker.Ack(b.lastTag, true)
+	b.pending = 0
+	return err
+}
@@ -0,0 +1,80 @@
+package rabbitmq
+
+import (
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakeAcknowledger records the acks sent by the batcher
+type fakeAcknowledger struct {
+	acks     int
+	lastTag  uint64
+	multiple bool
+}
+
+func (f *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	f.acks++
+	f.lastTag = tag
+	f.multiple = multiple
+	return nil
+}
+
+func (f *fakeAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error { return nil }
+
+func (f *fakeAcknowledger) Reject(tag uint64, requeue bool) error { return nil }
+
+func TestAckBatcherReducesAckCalls(t *testing.T) {
+	const messages = 250
+
+	acker := &fakeAcknowledger{}
+	b := newAckBatcher(&ConsumeOptions{ChannelID: "batch", BatchAck: &BatchAckOptions{Size: 100}})
+
+	for tag := uint64(1); tag <= messages; tag++ {
+		if err := b.add(amqp.Delivery{Acknowledger: acker, DeliveryTag: tag}); err != nil {
+			t.Fatalf("add(%d): %v", tag, err)
+		}
+	}
+	if acker.acks != 2 {
+		t.Fatalf("acks after %d messages = %d, want 2 (one per full batch)", messages, acker.acks)
+	}
+
+	// Shutdown flushes the partial batch
+	if err := b.flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if acker.acks != 3 {
+		t.Fatalf("acks after flush = %d, want 3 instead of %d individual acks", acker.acks, messages)
+	}
+	if acker.lastTag != messages || !acker.multiple {
+		t.Fatalf("last ack = (tag %d, multiple %v), want (tag %d, multiple true)", acker.lastTag, acker.multiple, messages)
+	}
+
+	// Nothing pending: no extra ack
+	if err := b.flush(); err != nil {
+		t.Fatalf("empty flush: %v", err)
+	}
+	if acker.acks != 3 {
+		t.Fatalf("empty flush sent an ack (%d acks)", acker.acks)
+	}
+}
+
+func TestAckBatcherDisabled(t *testing.T) {
+	if b := newAckBatcher(&ConsumeOptions{}); b != nil {
+		t.Fatal("batcher created without BatchAck")
+	}
+	if b := newAckBatcher(&ConsumeOptions{ChannelID: "batch", NoAck: true, BatchAck: &BatchAckOptions{}}); b != nil {
+		t.Fatal("batcher created with NoAck")
+	}
+}
+
+func TestBatchAckRequiresChannelID(t *testing.T) {
+	conn := NewConnection(Config{}, NewConsoleLogger(ConsoleLoggerOptions{HideDebug: true}))
+	handler := func(payload []byte, delivery amqp.Delivery) error { return nil }
+
+	err := ConsumeQueue(conn, "clicks", handler, &ConsumeOptions{BatchAck: &BatchAckOptions{}})
+	if !errors.Is(err, ErrBatchAckSharedChannel) {
+		t.Fatalf("err = %v, want ErrBatchAckSharedChannel", err)
+	}
+}
@@ -1,6 +1,7 @@
 package rabbitmq
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -17,6 +18,12 @@ type Connection struct {
 	defaultChannel *amqp.Channel
 	channels       map[string]*amqp.Channel // Named channels for isolation
 	consumerTags   map[string]string
+	consumers      []*activeConsumer // Consumers revived after a channel-level close
+	recovering     map[string]bool   // Channel IDs currently being recovered
+	handlers       sync.WaitGroup    // Consumer delivery loops still running
+	publishes      sync.WaitGroup    // Publish calls in progress
+	draining       bool
+	counters       connectionCounters // Reported by Stats
 	mu             sync.RWMutex
 	closed         bool
 }
@@ -32,6 +39,7 @@ func NewConnection(config Config, logger Logger) *Connection {
 		logger:       logger,
 		channels:     make(map[string]*amqp.Channel),
 		consumerTags: make(map[string]string),
+		recovering:   make(map[string]bool),
 		closed:       false,
 	}
 }
@@ -80,6 +88,7 @@ func (c *Connection) Connect() error {
 
 	c.conn = conn
 	c.defaultChannel = channel
+	c.counters.channelsOpened++
 
 	c.setupConnectionHandlers()
 
@@ -94,6 +103,11 @@ func (c *Connection) setupConnectionHandlers() {
 			return
 		}
 		closeErr := <-c.conn.NotifyClose(make(chan *amqp.Error))
+
+		c.mu.Lock()
+		c.counters.connectionCloses++
+		c.mu.Unlock()
+
 		if closeErr != nil {
 			c.logger.Error("RabbitMQ connection error", map[string]interface{}{
 				"error": closeErr.Error(),
@@ -125,27 +139,28 @@ func (c *Connection) setupChannelHandlers(channel *amqp.Channel, channelID strin
 		}
 
 		// Remove from map if it's a named channel
+		c.mu.Lock()
+		c.counters.channelCloses++
 		if channelID != "default" {
-			c.mu.Lock()
 			delete(c.channels, channelID)
-			c.mu.Unlock()
+		}
+		c.mu.Unlock()
+
+		// A channel-level error (e.g. PRECONDITION_FAILED) leaves the connection
+		// open, so re-open the channel and restart the consumers that used it
+		if closeErr != nil {
+			c.recoverChannel(channelID)
 		}
 	}()
 }
 
 // GetChannel returns a channel by ID
-// If channelID is empty, returns the default channel
+// If channelID is empty, returns the default channel (re-opened if it was closed while the connection is up)
 // If channelID is specified and doesn't exist, creates a new named channel
 func (c *Connection) GetChannel(channelID string) (*amqp.Channel, error) {
 	// Return default channel if no channelID specified
 	if channelID == "" {
-		c.mu.RLock()
-		defer c.mu.RUnlock()
-
-		if c.defaultChannel == nil {
-			return nil, errors.New("default channel not initialized. Call Connect() first")
-		}
-		return c.defaultChannel, nil
+		return c.getDefaultChannel()
 	}
 
 	// Check if named channel already exists
@@ -196,6 +211,7 @@ func (c *Connection) GetChannel(channelID string) (*amqp.Channel, error) {
 
 	c.setupChannelHandlers(channel, channelID)
 	c.channels[channelID] = channel
+	c.counters.channelsOpened++
 
 	c.logger.Info("Named channel created successfully", map[string]interface{}{
 		"channelId": channelID,
@@ -238,8 +254,18 @@ func (c *Connection) IsConnected() bool {
 	return c.conn != nil && c.defaultChannel != nil && !c.closed
 }
 
-// Close closes all channels and connection
+// Close closes all channels and connection without waiting for in-flight
+// handlers or publishes. Use Drain for a graceful shutdown.
 func (c *Connection) Close() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // don't wait
+
+	_, closeErr := c.drain(ctx)
+	return closeErr
+}
+
+// closeAll closes all channels and the connection
+func (c *Connection) closeAll() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -288,6 +314,7 @@ func (c *Connection) Close() error {
 	}
 
 	c.consumerTags = make(map[string]string)
+	c.consumers = nil
 	c.closed = true
 
 	c.logger.Info("RabbitMQ connection closed", nil)
@@ -0,0 +1,123 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ConsumeSpec pairs a queue with its handler and options for ConsumeMany
+type ConsumeSpec struct {
+	Queue   string
+	Handler MessageHandler
+	Options *ConsumeOptions // nil uses the ConsumeQueue defaults
+}
+
+// Consumer is a consumer started by ConsumeMany. Like ConsumeQueue consumers
+// it is revived by channel recovery and cancelled by Drain.
+type Consumer struct {
+	conn   *Connection
+	active *activeConsumer
+}
+
+// Queue returns the consumed queue
+func (c *Consumer) Queue() string {
+	return c.active.queue
+}
+
+// ConsumerTag returns the tag the consumer was registered with (generated when
+// the options left it empty)
+func (c *Consumer) ConsumerTag() string {
+	return c.active.options.ConsumerTag
+}
+
+// Stop cancels the consumer and waits until its delivery loop has handled the
+// messages already received (or requeued them with RequeueOnStop), or until ctx
+// expires, in which case ctx.Err() is returned.
+func (c *Consumer) Stop(ctx context.Context) error {
+	if err := c.cancel(); err != nil {
+		return err
+	}
+	return c.wait(ctx)
+}
+
+// cancel stops reviving the consumer and sends basic.cancel on its channel
+func (c *Consumer) cancel() error {
+	c.conn.untrackConsumer(c.active.options.ConsumerTag)
+	c.active.markStopped()
+	return cancelConsumerOnChannel(c.conn, c.active.options.ChannelID, c.active.options.ConsumerTag)
+}
+
+// wait blocks until the consumer's delivery loops have exited or ctx expires
+func (c *Consumer) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.active.loops.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ConsumeMany starts one consumer per spec, in order. If any of them fails to
+// start, the consumers already started are cancelled and the error is returned,
+// so either every queue is consumed or none is.
+//
+// Each spec's options are copied, including QueueOptions and its Args, so specs
+// may share one *ConsumeOptions (and one *QueueOptions): the DLX arguments that
+// EnableDLQ adds for one queue never reach another. Give them distinct
+// ConsumerTags (or leave them empty) and use a ChannelID per spec where
+// isolation matters.
+func ConsumeMany(conn *Connection, specs []ConsumeSpec) ([]*Consumer, error) {
+	for i, spec := range specs {
+		if spec.Queue == "" {
+			return nil, fmt.Errorf("ConsumeMany: spec %d has no queue", i)
+		}
+		if spec.Handler == nil {
+			return nil, fmt.Errorf("ConsumeMany: spec %d (queue %s) has no handler", i, spec.Queue)
+		}
+	}
+
+	consumers := make([]*Consumer, 0, len(specs))
+	for _, spec := range specs {
+		active, err := consumeQueue(conn, spec.Queue, spec.Handler.consumeHandler(), spec.Options)
+		if err != nil {
+			for _, started := range consumers {
+				if cancelErr := started.cancel(); cancelErr != nil {
+					conn.GetLogger().Error("Failed to roll back consumer", map[string]interface{}{
+						"error": cancelErr.Error(),
+						"queue": started.Queue(),
+					})
+				}
+			}
+			return nil, err
+		}
+
+		consumers = append(consumers, &Consumer{conn: conn, active: active})
+	}
+
+	return consumers, nil
+}
+
+// StopAll cancels every consumer first, so they stop receiving at the same
+// time, then waits for all of them as Consumer.Stop does. Errors are joined.
+func StopAll(ctx context.Context, consumers []*Consumer) error {
+	var errs []error
+	for _, c := range consumers {
+		if err := c.cancel(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, c := range consumers {
+		if err := c.wait(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("consumer on queue %s: %w", c.Queue(), err))
+			break // ctx expired, the remaining waits would fail the same way
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,123 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ConsumeN consumes from queue until options.MaxMessages messages have been
+// processed successfully (acked), then cancels the consumer and returns.
+// It blocks, and is meant for batch or cron jobs that drain part of a queue
+// and exit; use ConsumeQueue for long-running workers.
+//
+// With options.IdleTimeout set, ConsumeN also stops once no message has been
+// delivered for that long, which covers an empty queue. Messages already
+// prefetched when the limit is reached are requeued. Failed messages go through
+// the RetryStrategy / DLQ as usual and do not count towards MaxMessages.
+//
+// The consumer is not revived by channel recovery; if its channel closes early
+// ConsumeN returns the count so far together with an error.
+func ConsumeN(
+	conn *Connection,
+	queue string,
+	handler MessageHandler,
+	options *ConsumeOptions,
+) (int, error) {
+	if options == nil || options.MaxMessages <= 0 {
+		return 0, errors.New("ConsumeN requires options.MaxMessages > 0")
+	}
+
+	limit := int64(options.MaxMessages)
+	inner := handler.consumeHandler()
+
+	var processed atomic.Int64
+	reached := make(chan struct{})
+	activity := make(chan struct{}, 1)
+
+	counting := func(ctx context.Context, payload []byte, delivery amqp.Delivery, ack *Acknowledger) error {
+		// Prefetched deliveries after the limit go back to the queue untouched
+		if processed.Load() >= limit {
+			ack.RequeueNow()
+			return nil
+		}
+
+		err := inner(ctx, payload, delivery, ack)
+		if resolveAction(ack.Action(), err) == ActionAck && processed.Add(1) == limit {
+			close(reached)
+		}
+
+		select {
+		case activity <- struct{}{}:
+		default:
+		}
+		return err
+	}
+
+	// Works on a copy, so the generated consumer tag doesn't leak into the caller's options
+	consumer := newActiveConsumer(queue, counting, options)
+	opts := consumer.options
+
+	finished := make(chan struct{})
+	if err := startConsuming(conn, consumer, finished); err != nil {
+		return 0, err
+	}
+
+	logger := conn.GetLogger()
+
+	// idle stays nil (never fires) without an IdleTimeout
+	var idle <-chan time.Time
+	if opts.IdleTimeout > 0 {
+		idle = time.After(opts.IdleTimeout)
+	}
+
+	var stopErr error
+wait:
+	for {
+		select {
+		case <-reached:
+			break wait
+		case <-idle:
+			logger.Info("ConsumeN idle timeout reached", map[string]interface{}{
+				"queue":     queue,
+				"processed": processed.Load(),
+			})
+			break wait
+		case <-activity:
+			if opts.IdleTimeout > 0 {
+				idle = time.After(opts.IdleTimeout)
+			}
+		case <-finished:
+			stopErr = fmt.Errorf("consumer on queue %s stopped after %d of %d messages", queue, processed.Load(), limit)
+			break wait
+		}
+	}
+
+	if stopErr == nil {
+		consumer.markStopped()
+		if err := cancelConsumerOnChannel(conn, opts.ChannelID, opts.ConsumerTag); err != nil {
+			stopErr = err
+		} else {
+			// Let the delivery loop handle (requeue) anything still buffered
+			<-finished
+		}
+	}
+
+	return int(processed.Load()), stopErr
+}
+
+// cancelConsumerOnChannel cancels consumerTag on the channel it was started on
+func cancelConsumerOnChannel(conn *Connection, channelID, consumerTag string) error {
+	channel, err := conn.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+	if err := channel.Cancel(consumerTag, false); err != nil {
+		return fmt.Errorf("failed to cancel consumer %s: %w", consumerTag, err)
+	}
+	return nil
+}
@@ -1,24 +1,98 @@
 package rabbitmq
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-// ConsumeQueue starts consuming messages from a queue
+// ConsumeQueue starts consuming messages from a queue.
+// options is copied and never modified; an empty ConsumerTag gets a generated,
+// process-unique tag, so one options value can be reused for several consumers.
 func ConsumeQueue(
 	conn *Connection,
 	queue string,
 	handler MessageHandler,
 	options *ConsumeOptions,
 ) error {
-	// Use default options if not provided
-	if options == nil {
-		options = &ConsumeOptions{
-			NoAck:     false,
-			Exclusive: false,
+	return ConsumeQueueContext(context.Background(), conn, queue, handler, options)
+}
+
+// ConsumeQueueContext starts consuming like ConsumeQueue and cancels the
+// consumer once ctx is done: it stops being revived by channel recovery,
+// basic.cancel is sent, and the delivery loop exits after handling the
+// messages already received (or requeueing them with RequeueOnStop).
+// It returns ctx.Err() without consuming when ctx is already done.
+func ConsumeQueueContext(
+	ctx context.Context,
+	conn *Connection,
+	queue string,
+	handler MessageHandler,
+	options *ConsumeOptions,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	active, err := consumeQueue(conn, queue, handler.consumeHandler(), options)
+	if err != nil {
+		return err
+	}
+
+	consumer := &Consumer{conn: conn, active: active}
+	context.AfterFunc(ctx, func() {
+		if err := consumer.cancel(); err != nil {
+			conn.GetLogger().Error("Failed to cancel consumer on context done", map[string]interface{}{
+				"error":       err.Error(),
+				"queue":       queue,
+				"consumerTag": consumer.ConsumerTag(),
+			})
 		}
+	})
+
+	return nil
+}
+
+// consumeQueue is shared by ConsumeQueue and ConsumeQueueWithAck.
+// It works on a private copy of options; the caller's value is never modified.
+func consumeQueue(
+	conn *Connection,
+	queue string,
+	handler consumeHandler,
+	options *ConsumeOptions,
+) (*activeConsumer, error) {
+	consumer := newActiveConsumer(queue, handler, options)
+	if err := startConsuming(conn, consumer, nil); err != nil {
+		return nil, err
+	}
+
+	// Track the consumer so it can be revived if its channel is closed by the broker
+	conn.trackConsumer(consumer)
+
+	return consumer, nil
+}
+
+// consumerSeq makes generated consumer tags unique within the process
+var consumerSeq atomic.Uint64
+
+// startConsuming declares the topology (DLQ, queue, retry strategy) on the
+// consumer's channel and starts delivering messages to handler.
+// It is also used to restart a consumer after its channel has been re-opened.
+// finished, when not nil, is closed once the delivery loop has exited.
+func startConsuming(
+	conn *Connection,
+	consumer *activeConsumer,
+	finished chan struct{},
+) error {
+	queue, options := consumer.queue, consumer.options
+
+	if options.BatchAck != nil && !options.NoAck && options.ChannelID == "" {
+		return fmt.Errorf("consumer on queue %s: %w", queue, ErrBatchAckSharedChannel)
 	}
 
 	channel, err := conn.GetChannel(options.ChannelID)
@@ -28,14 +102,26 @@ func ConsumeQueue(
 
 	logger := conn.GetLogger()
 
+	consumeArgs, err := options.consumeArgs()
+	if err != nil {
+		return err
+	}
+
+	// Streams refuse consumers without QoS; re-applied here on channel recovery too
+	if options.StreamOffset != "" && conn.config.Prefetch <= 0 {
+		if err := channel.Qos(DefaultStreamPrefetch, 0, false); err != nil {
+			return fmt.Errorf("failed to set prefetch for stream consumer on queue %s: %w", queue, err)
+		}
+	}
+
 	// Use default queue options if not provided
 	if options.QueueOptions == nil {
 		defaultQueueOpts := DefaultQueueOptions()
 		options.QueueOptions = &defaultQueueOpts
 	}
 
-	// Setup DLQ if enabled
-	if options.EnableDLQ {
+	// Setup DLQ if enabled (pre-declared in passive mode)
+	if options.EnableDLQ && !options.PassiveDeclare {
 		if err := setupDLQ(channel, queue, options.QueueOptions); err != nil {
 			logger.Error("Failed to setup DLQ", map[string]interface{}{
 				"error": err.Error(),
@@ -49,15 +135,17 @@ func ConsumeQueue(
 		})
 	}
 
+	// Declare the exchanges referenced by Bindings before the queue
+	if err := declareBindingExchanges(channel, options); err != nil {
+		logger.Error("Failed to declare binding exchanges", map[string]interface{}{
+			"error": err.Error(),
+			"queue": queue,
+		})
+		return err
+	}
+
 	// Assert queue first (must exist before retry strategy binds it)
-	_, err = channel.QueueDeclare(
-		queue,
-		options.QueueOptions.Durable,
-		options.QueueOptions.AutoDelete,
-		options.QueueOptions.Exclusive,
-		options.QueueOptions.NoWait,
-		options.QueueOptions.Args,
-	)
+	err = declareQueue(channel, queue, options.QueueOptions, options.PassiveDeclare)
 	if err != nil {
 		channelID := "default"
 		if options.ChannelID != "" {
@@ -71,8 +159,19 @@ func ConsumeQueue(
 		return fmt.Errorf("failed to declare queue %s: %w", queue, err)
 	}
 
-	// Setup retry strategy after queue is declared
-	if options.RetryStrategy != nil {
+	// Bind the queue to the exchanges declared above (pre-bound in passive mode)
+	if !options.PassiveDeclare {
+		if err := bindQueue(channel, queue, options.Bindings); err != nil {
+			logger.Error("Failed to bind queue", map[string]interface{}{
+				"error": err.Error(),
+				"queue": queue,
+			})
+			return err
+		}
+	}
+
+	// Setup retry strategy after queue is declared (pre-declared in passive mode)
+	if options.RetryStrategy != nil && !options.PassiveDeclare {
 		if err := options.RetryStrategy.Setup(channel, queue); err != nil {
 			channelID := "default"
 			if options.ChannelID != "" {
@@ -87,6 +186,12 @@ func ConsumeQueue(
 		}
 	}
 
+	// Track the delivery loop so Drain can wait for in-flight handlers
+	done, err := conn.beginConsume(queue)
+	if err != nil {
+		return err
+	}
+
 	// Start consuming
 	msgs, err := channel.Consume(
 		queue,
@@ -95,7 +200,7 @@ func ConsumeQueue(
 		options.Exclusive,
 		false, // no-local
 		options.NoWait,
-		options.Args,
+		consumeArgs,
 	)
 	if err != nil {
 		channelID := "default"
@@ -107,6 +212,7 @@ func ConsumeQueue(
 			"queue":     queue,
 			"channelId": channelID,
 		})
+		done()
 		return fmt.Errorf("failed to start consuming queue %s: %w", queue, err)
 	}
 
@@ -121,13 +227,43 @@ func ConsumeQueue(
 	})
 
 	// Process messages
+	consumer.loops.Add(1)
 	go func() {
-		for msg := range msgs {
-			if err := processMessage(conn, msg, handler, options); err != nil {
-				logger.Error("Error processing message", map[string]interface{}{
-					"error": err.Error(),
-					"queue": queue,
-				})
+		defer consumer.loops.Done()
+		defer done()
+		if finished != nil {
+			defer close(finished)
+		}
+
+		// flushTick stays nil (never fires) without batch acking
+		batch := newAckBatcher(options)
+		var flushTick <-chan time.Time
+		if batch != nil {
+			ticker := time.NewTicker(batch.interval)
+			defer ticker.Stop()
+			flushTick = ticker.C
+		}
+
+		for {
+			select {
+			case msg, ok := <-msgs:
+				if !ok {
+					// Consumer cancelled or channel closed: ack what was handled so far
+					flushBatch(logger, queue, batch)
+					return
+				}
+				if options.RequeueOnStop && !options.NoAck && consumer.isStopped() {
+					requeueOnStop(logger, queue, msg, batch)
+					continue
+				}
+				if err := processMessage(conn, consumer, msg, batch); err != nil {
+					logger.Error("Error processing message", map[string]interface{}{
+						"error": err.Error(),
+						"queue": queue,
+					})
+				}
+			case <-flushTick:
+				flushBatch(logger, queue, batch)
 			}
 		}
 	}()
@@ -138,63 +274,203 @@ func ConsumeQueue(
 // processMessage handles a single message with retry logic
 func processMessage(
 	conn *Connection,
+	consumer *activeConsumer,
 	delivery amqp.Delivery,
-	handler MessageHandler,
-	options *ConsumeOptions,
+	batch *ackBatcher,
 ) error {
+	queue, handler, options := consumer.queue, consumer.handler, consumer.options
 	logger := conn.GetLogger()
+	received := time.Now()
 
-	channelID := ""
-	if options != nil {
-		channelID = options.ChannelID
-	}
+	channelID := options.ChannelID
 
 	channel, err := conn.GetChannel(channelID)
 	if err != nil {
 		return err
 	}
 
+	// Skip messages this consumer already handled (producer retries)
+	dedupID := ""
+	if consumer.dedup != nil {
+		dedupID = deduplicationID(delivery)
+		if dedupID != "" && consumer.dedup.seen(dedupID) {
+			logger.Debug("Skipping duplicate message", map[string]interface{}{
+				"queue":           queue,
+				"deduplicationId": dedupID,
+			})
+			if options.NoAck {
+				return nil
+			}
+			if batch != nil {
+				return batch.add(delivery)
+			}
+			return delivery.Ack(false)
+		}
+	}
+
 	// Execute handler
-	err = handler(delivery.Body, delivery)
+	start := time.Now()
+	ack, err := runHandler(handler, delivery, options.HandlerTimeout)
+	if errors.Is(err, ErrHandlerTimeout) {
+		logger.Error("Message handler timed out, treating as failure", map[string]interface{}{
+			"queue":   queue,
+			"timeout": options.HandlerTimeout.String(),
+		})
+	}
+	action := resolveAction(ack.Action(), err)
+	attempt := GetRetryMetadata(delivery).AttemptCount
+
+	// Logged once the message is settled, so the duration covers receive to ack/nack
+	defer func() {
+		logger.Debug("Message processed", map[string]interface{}{
+			"queue":        queue,
+			"action":       action.String(),
+			"durationMs":   time.Since(received).Milliseconds(),
+			"attemptCount": attempt,
+			"payloadSize":  len(delivery.Body),
+			"channelId":    channelID,
+		})
+	}()
 
-	if err != nil {
-		// Handler failed, check if we should retry
-		if options.RetryStrategy != nil && options.RetryStrategy.ShouldRetry(delivery) {
-			logger.Debug("Message failed, applying retry strategy", map[string]interface{}{
-				"error": err.Error(),
-			})
+	// Only successes are remembered, so a failed message can still be retried
+	if dedupID != "" && action == ActionAck {
+		consumer.dedup.record(dedupID)
+	}
 
-			// Use retry strategy to handle failure
-			if retryErr := options.RetryStrategy.HandleFailure(channel, delivery); retryErr != nil {
-				logger.Error("Failed to apply retry strategy", map[string]interface{}{
-					"error": retryErr.Error(),
-				})
-				// Nack without requeue if retry strategy fails
-				return delivery.Nack(false, false)
-			}
+	if options.OnMessageProcessed != nil {
+		options.OnMessageProcessed(queue, action == ActionAck, attempt, time.Since(start))
+	}
 
-			// Ack the original message (retry strategy will handle redelivery)
-			return delivery.Ack(false)
+	// With auto-ack the broker has already considered the message delivered
+	if options.NoAck || action == ActionSettled {
+		return nil
+	}
+
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	if batch != nil {
+		if action == ActionAck {
+			return batch.add(delivery)
 		}
+		// Settle the earlier successes first so a later multiple ack can't cover this message
+		if err := batch.flush(); err != nil {
+			return fmt.Errorf("failed to flush batched acks: %w", err)
+		}
+	}
 
-		// No retry strategy or retry limit exceeded, nack without requeue
-		logger.Error("Message processing failed, no retry", map[string]interface{}{
-			"error": err.Error(),
+	switch action {
+	case ActionAck:
+		return delivery.Ack(false)
+
+	case ActionRequeue:
+		logger.Debug("Requeueing message", map[string]interface{}{
+			"error": errMsg,
+			"queue": queue,
+		})
+		return delivery.Nack(false, true)
+
+	case ActionDiscard:
+		logger.Error("Discarding message, skipping retry", map[string]interface{}{
+			"error": errMsg,
+			"queue": queue,
 		})
 		return delivery.Nack(false, false)
 	}
 
-	// Success, ack the message
-	if !options.NoAck {
+	// ActionRetry: check if the retry strategy can still redeliver the message
+	if options.RetryStrategy != nil && options.RetryStrategy.ShouldRetry(delivery) {
+		logger.Debug("Message failed, applying retry strategy", map[string]interface{}{
+			"error": errMsg,
+		})
+
+		// Use retry strategy to handle failure
+		if retryErr := options.RetryStrategy.HandleFailure(channel, delivery); retryErr != nil {
+			logger.Error("Failed to apply retry strategy", map[string]interface{}{
+				"error": retryErr.Error(),
+			})
+			// Nack without requeue if retry strategy fails
+			return delivery.Nack(false, false)
+		}
+
+		// Ack the original message (retry strategy will handle redelivery)
 		return delivery.Ack(false)
 	}
 
-	return nil
+	// No retry strategy or retry limit exceeded, nack without requeue
+	logger.Error("Message processing failed, no retry", map[string]interface{}{
+		"error": errMsg,
+	})
+	return delivery.Nack(false, false)
+}
+
+// runHandler calls the handler, bounded by timeout when it is positive.
+// On timeout the handler is abandoned (its context is cancelled, its ack
+// decisions are ignored) and ErrHandlerTimeout is returned, so the message goes
+// through the retry strategy and the delivery loop moves on.
+func runHandler(handler consumeHandler, delivery amqp.Delivery, timeout time.Duration) (Acknowledger, error) {
+	if timeout <= 0 {
+		var ack Acknowledger
+		err := handler(context.Background(), delivery.Body, delivery, &ack)
+		return ack, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	type outcome struct {
+		ack Acknowledger
+		err error
+	}
+	result := make(chan outcome, 1)
+	go func() {
+		var ack Acknowledger
+		err := handler(ctx, delivery.Body, delivery, &ack)
+		result <- outcome{ack: ack, err: err}
+	}()
+
+	select {
+	case r := <-result:
+		return r.ack, r.err
+	case <-ctx.Done():
+		return Acknowledger{}, fmt.Errorf("%w after %s", ErrHandlerTimeout, timeout)
+	}
+}
+
+// requeueOnStop returns a delivery that was buffered when the consumer stopped
+// to the broker without handling it
+func requeueOnStop(logger Logger, queue string, delivery amqp.Delivery, batch *ackBatcher) {
+	flushBatch(logger, queue, batch)
+	if err := delivery.Nack(false, true); err != nil {
+		logger.Error("Failed to requeue message on stop", map[string]interface{}{
+			"error": err.Error(),
+			"queue": queue,
+		})
+	}
+}
+
+// flushBatch flushes pending batched acks, logging failures (the messages will be redelivered)
+func flushBatch(logger Logger, queue string, batch *ackBatcher) {
+	if batch == nil {
+		return
+	}
+	if err := batch.flush(); err != nil {
+		logger.Error("Failed to flush batched acks", map[string]interface{}{
+			"error": err.Error(),
+			"queue": queue,
+		})
+	}
 }
 
 // CancelConsumer cancels a consumer by its tag
 // Uses default channel for cancellation
 func CancelConsumer(conn *Connection, consumerTag string) error {
+	if consumer := conn.untrackConsumer(consumerTag); consumer != nil {
+		consumer.markStopped()
+	}
+
 	channel, err := conn.GetChannel("") // Use default channel
 	if err != nil {
 		return err
@@ -242,6 +518,55 @@ func GetRetryMetadata(delivery amqp.Delivery) RetryMetadata {
 	return metadata
 }
 
+// declareBindingExchanges declares each exchange referenced by options.Bindings once
+func declareBindingExchanges(channel *amqp.Channel, options *ConsumeOptions) error {
+	if len(options.Bindings) == 0 {
+		return nil
+	}
+
+	exchangeOptions := options.ExchangeOptions
+	if exchangeOptions == nil {
+		defaultExchangeOpts := DefaultExchangeOptions()
+		exchangeOptions = &defaultExchangeOpts
+	}
+
+	declared := make(map[string]bool)
+	for _, binding := range options.Bindings {
+		if binding.Exchange == "" {
+			return errors.New("queue binding has no exchange")
+		}
+		if declared[binding.Exchange] || strings.HasPrefix(binding.Exchange, "amq.") {
+			continue
+		}
+
+		err := declareExchange(channel, binding.Exchange, exchangeOptions, options.PassiveDeclare)
+		if err != nil {
+			return fmt.Errorf("failed to declare exchange %s: %w", binding.Exchange, err)
+		}
+		declared[binding.Exchange] = true
+	}
+
+	return nil
+}
+
+// bindQueue binds the queue to each exchange in order
+func bindQueue(channel *amqp.Channel, queue string, bindings []QueueBinding) error {
+	for _, binding := range bindings {
+		err := channel.QueueBind(
+			queue,
+			binding.RoutingKey,
+			binding.Exchange,
+			false,
+			binding.Args,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to bind queue %s to exchange %s (key %q): %w", queue, binding.Exchange, binding.RoutingKey, err)
+		}
+	}
+
+	return nil
+}
+
 // setupDLQ sets up Dead Letter Queue infrastructure
 func setupDLQ(channel *amqp.Channel, originalQueue string, queueOptions *QueueOptions) error {
 	dlxName := fmt.Sprintf("%s.failed.dlx", originalQueue)
@@ -0,0 +1,104 @@
+package rabbitmq
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeduplicationHeader carries PublishOptions.DeduplicationID
+const DeduplicationHeader = "x-deduplication-id"
+
+const (
+	// DefaultDeduplicationSize is the number of IDs remembered per consumer
+	DefaultDeduplicationSize = 10000
+	// DefaultDeduplicationTTL is how long an ID is remembered
+	DefaultDeduplicationTTL = 10 * time.Minute
+)
+
+// DeduplicationOptions enables best-effort duplicate detection on a consumer:
+// a message whose x-deduplication-id was already handled successfully within
+// TTL is acked without calling the handler.
+//
+// The IDs live in an in-memory LRU per consumer (at most Size entries), so
+// duplicates are only caught within one process and are forgotten on restart
+// or when evicted. The broker does not enforce anything.
+type DeduplicationOptions struct {
+	Size int           // Max IDs remembered (DefaultDeduplicationSize when <= 0)
+	TTL  time.Duration // How long an ID is remembered (DefaultDeduplicationTTL when <= 0)
+}
+
+// dedupCache is an LRU of recently handled deduplication IDs with expiry
+type dedupCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // front = most recently seen
+	entries map[string]*list.Element
+}
+
+type dedupEntry struct {
+	id      string
+	expires time.Time
+}
+
+func newDedupCache(opts *DeduplicationOptions) *dedupCache {
+	c := &dedupCache{
+		size:    opts.Size,
+		ttl:     opts.TTL,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	if c.size <= 0 {
+		c.size = DefaultDeduplicationSize
+	}
+	if c.ttl <= 0 {
+		c.ttl = DefaultDeduplicationTTL
+	}
+	return c
+}
+
+// seen reports whether id was recorded and has not expired
+func (c *dedupCache) seen(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[id]
+	if !ok {
+		return false
+	}
+	if time.Now().After(elem.Value.(*dedupEntry).expires) {
+		c.order.Remove(elem)
+		delete(c.entries, id)
+		return false
+	}
+	return true
+}
+
+// record remembers id, evicting the least recently recorded IDs beyond size
+func (c *dedupCache) record(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(c.ttl)
+	if elem, ok := c.entries[id]; ok {
+		elem.Value.(*dedupEntry).expires = expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[id] = c.order.PushFront(&dedupEntry{id: id, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*dedupEntry).id)
+	}
+}
+
+// deduplicationID returns the message's x-deduplication-id header, if any
+func deduplicationID(delivery amqp.Delivery) string {
+	id, _ := delivery.Headers[DeduplicationHeader].(string)
+	return id
+}
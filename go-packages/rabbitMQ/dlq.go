@@ -0,0 +1,205 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DrainOptions configures DrainDLQ
+type DrainOptions struct {
+	MaxCount  int    // Maximum messages to inspect (0 = until the DLQ is empty)
+	Republish bool   // Re-publish approved messages to their original queue (otherwise approved messages are discarded)
+	DryRun    bool   // Only report what is in the DLQ; every message is put back untouched
+	ChannelID string // Optional channel ID. Empty string uses the default channel.
+}
+
+// DrainDLQ inspects the dead letter queue of queue (<queue>.failed) and re-drives or
+// discards its messages.
+//
+// For each message handler is called: returning nil approves it (re-published to the
+// original queue when opts.Republish is set, then removed from the DLQ), returning an
+// error keeps it in the DLQ. The original queue is read from the x-original-queue header
+// set by the retry strategies and falls back to queue. Re-published messages get their
+// retry headers reset so they receive the full retry budget again.
+//
+// Kept messages stay unacked until the drain finishes, so each message is seen once.
+func DrainDLQ(conn *Connection, queue string, handler MessageHandler, opts *DrainOptions) error {
+	if opts == nil {
+		opts = &DrainOptions{}
+	}
+	if handler == nil && !opts.DryRun {
+		return errors.New("handler is required unless DryRun is set")
+	}
+
+	channel, err := conn.GetChannel(opts.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	logger := conn.GetLogger()
+	dlqName := fmt.Sprintf("%s.failed", queue)
+
+	var kept []amqp.Delivery
+	inspected, republished, discarded := 0, 0, 0
+
+	// Put kept messages back in the DLQ once we're done
+	defer func() {
+		for _, d := range kept {
+			if err := d.Nack(false, true); err != nil {
+				logger.Error("Failed to return message to DLQ", map[string]interface{}{
+					"error": err.Error(),
+					"dlq":   dlqName,
+				})
+			}
+		}
+	}()
+
+	for opts.MaxCount == 0 || inspected < opts.MaxCount {
+		delivery, ok, err := channel.Get(dlqName, false)
+		if err != nil {
+			logger.Error("Failed to get message from DLQ", map[string]interface{}{
+				"error": err.Error(),
+				"dlq":   dlqName,
+			})
+			return fmt.Errorf("failed to get message from DLQ %s: %w", dlqName, err)
+		}
+		if !ok {
+			break // DLQ is empty
+		}
+		inspected++
+
+		metadata := GetRetryMetadata(delivery)
+		target := metadata.OriginalQueue
+		if target == "" {
+			target = queue
+		}
+
+		if opts.DryRun {
+			logger.Info("DLQ message", map[string]interface{}{
+				"dlq":           dlqName,
+				"originalQueue": target,
+				"attempts":      metadata.AttemptCount,
+				"firstFailedAt": metadata.FirstFailedAt,
+				"payloadSize":   len(delivery.Body),
+			})
+			kept = append(kept, delivery)
+			continue
+		}
+
+		if err := handler(delivery.Body, delivery); err != nil {
+			logger.Debug("DLQ message kept", map[string]interface{}{
+				"dlq":   dlqName,
+				"error": err.Error(),
+			})
+			kept = append(kept, delivery)
+			continue
+		}
+
+		if opts.Republish {
+			if err := republish(channel, target, delivery); err != nil {
+				logger.Error("Failed to re-publish DLQ message", map[string]interface{}{
+					"error": err.Error(),
+					"queue": target,
+				})
+				kept = append(kept, delivery)
+				continue
+			}
+			republished++
+		} else {
+			discarded++
+		}
+
+		if err := delivery.Ack(false); err != nil {
+			return fmt.Errorf("failed to ack DLQ message: %w", err)
+		}
+	}
+
+	logger.Info("DLQ drain finished", map[string]interface{}{
+		"dlq":         dlqName,
+		"inspected":   inspected,
+		"republished": republished,
+		"discarded":   discarded,
+		"kept":        len(kept),
+		"dryRun":      opts.DryRun,
+	})
+
+	return nil
+}
+
+// republish sends a dead-lettered message back to queue with its retry headers reset
+func republish(channel *amqp.Channel, queue string, delivery amqp.Delivery) error {
+	headers := amqp.Table{}
+	for k, v := range delivery.Headers {
+		switch k {
+		case "x-retry-count", "x-first-failed-at", "x-death", "x-first-death-exchange", "x-first-death-queue", "x-first-death-reason":
+			continue
+		}
+		headers[k] = v
+	}
+
+	return channel.PublishWithContext(
+		context.Background(),
+		"",    // default exchange
+		queue, // routing key
+		false, // mandatory
+		false, // immediate
+		amqp.Publishing{
+			ContentType:  delivery.ContentType,
+			Body:         delivery.Body,
+			DeliveryMode: delivery.DeliveryMode,
+			Priority:     delivery.Priority,
+			Headers:      headers,
+		},
+	)
+}
+
+// SendToDLQ moves delivery to the dead letter queue of queue (<queue>.failed)
+// right away, without going through the retry strategy: it publishes a copy via
+// <queue>.failed.dlx with the original headers, then acks the original.
+//
+// The DLQ must have been set up by a consumer with EnableDLQ; otherwise the DLX
+// does not exist and the publish fails (closing the channel).
+//
+// When called from a ConsumeQueueWithAck handler, call ack.Settled() as well so
+// the consumer doesn't settle the delivery a second time.
+func SendToDLQ(channel *amqp.Channel, delivery amqp.Delivery, queue string) error {
+	dlxName := fmt.Sprintf("%s.failed.dlx", queue)
+	dlqName := fmt.Sprintf("%s.failed", queue)
+
+	headers := amqp.Table{}
+	for k, v := range delivery.Headers {
+		headers[k] = v
+	}
+	// DrainDLQ re-drives messages to the queue named here
+	if _, ok := headers["x-original-queue"]; !ok {
+		headers["x-original-queue"] = queue
+	}
+
+	err := channel.PublishWithContext(
+		context.Background(),
+		dlxName, // exchange
+		dlqName, // routing key
+		false,   // mandatory
+		false,   // immediate
+		amqp.Publishing{
+			ContentType:  delivery.ContentType,
+			Body:         delivery.Body,
+			DeliveryMode: delivery.DeliveryMode,
+			Priority:     delivery.Priority,
+			MessageId:    delivery.MessageId,
+			Timestamp:    delivery.Timestamp,
+			Headers:      headers,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish message to DLQ %s: %w", dlqName, err)
+	}
+
+	if err := delivery.Ack(false); err != nil {
+		return fmt.Errorf("failed to ack message sent to DLQ: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDraining is returned when publishing or consuming on a connection that is being drained
+var ErrDraining = errors.New("connection is draining")
+
+// Drain gracefully shuts the connection down:
+//  1. cancels every consumer started with ConsumeQueue (no new deliveries),
+//  2. waits until their delivery loops have handled the messages already received
+//     and any in-progress publish calls have returned,
+//  3. closes channels and the connection.
+//
+// If ctx expires before step 2 finishes, the connection is closed anyway and
+// ctx.Err() is returned. The package does not use publisher confirms, so once a
+// publish call has returned there is nothing more to wait for.
+func (c *Connection) Drain(ctx context.Context) error {
+	waitErr, closeErr := c.drain(ctx)
+	if closeErr != nil {
+		return closeErr
+	}
+	return waitErr
+}
+
+// drain returns the wait error (ctx expired) and the close error separately,
+// so Close can keep its abrupt behaviour without reporting the cancelled context
+func (c *Connection) drain(ctx context.Context) (waitErr error, closeErr error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, nil
+	}
+	c.draining = true
+	consumers := append([]*activeConsumer(nil), c.consumers...)
+	c.consumers = nil // don't revive consumers on channel close from now on
+	c.mu.Unlock()
+
+	c.cancelConsumers(consumers)
+
+	done := make(chan struct{})
+	go func() {
+		c.handlers.Wait()
+		c.publishes.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		c.logger.Info("RabbitMQ drained", nil)
+	case <-ctx.Done():
+		waitErr = ctx.Err()
+		if !errors.Is(waitErr, context.Canceled) {
+			c.logger.Warn("RabbitMQ drain timed out, closing with work in flight", map[string]interface{}{
+				"error": waitErr.Error(),
+			})
+		}
+	}
+
+	return waitErr, c.closeAll()
+}
+
+// cancelConsumers sends basic.cancel for every consumer so the broker stops delivering
+func (c *Connection) cancelConsumers(consumers []*activeConsumer) {
+	for _, consumer := range consumers {
+		consumer.markStopped()
+		channel, err := c.GetChannel(consumer.options.ChannelID)
+		if err != nil {
+			continue
+		}
+		if err := channel.Cancel(consumer.options.ConsumerTag, false); err != nil {
+			c.logger.Error("Failed to cancel consumer", map[string]interface{}{
+				"error":       err.Error(),
+				"queue":       consumer.queue,
+				"consumerTag": consumer.options.ConsumerTag,
+			})
+		}
+	}
+}
+
+// beginPublish registers an in-progress publish; call the returned func when it returns
+func (c *Connection) beginPublish() (func(), error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.draining {
+		return nil, ErrDraining
+	}
+	c.publishes.Add(1)
+	return c.publishes.Done, nil
+}
+
+// beginConsume registers a consumer delivery loop; call the returned func when it exits
+func (c *Connection) beginConsume(queue string) (func(), error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.draining {
+		return nil, fmt.Errorf("cannot consume queue %s: %w", queue, ErrDraining)
+	}
+	c.handlers.Add(1)
+	return c.handlers.Done, nil
+}
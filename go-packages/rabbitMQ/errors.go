@@ -0,0 +1,62 @@
+package rabbitmq
+
+import "errors"
+
+// ErrTopologyNotFound is returned in passive mode when a queue or exchange the
+// caller expects to be pre-declared does not exist
+var ErrTopologyNotFound = errors.New("not found (passive declare)")
+
+// ErrInvalidRoutingKey is returned when a routing key is empty (when derived by
+// PublishOptions.RoutingKeyFunc) or longer than the AMQP limit of 255 bytes
+var ErrInvalidRoutingKey = errors.New("invalid routing key")
+
+// ErrInvalidQueueType is returned when QueueOptions.QueueType is not one of the
+// supported types, or is combined with flags the type does not allow
+var ErrInvalidQueueType = errors.New("invalid queue type")
+
+// ErrInvalidStreamOffset is returned when ConsumeOptions.StreamOffset is not
+// first, last, next, an integer offset or an RFC 3339 timestamp, or when it is
+// combined with NoAck
+var ErrInvalidStreamOffset = errors.New("invalid stream offset")
+
+// ErrBatchAckSharedChannel is returned when ConsumeOptions.BatchAck is set
+// without a ChannelID: a multiple=true ack on the shared default channel would
+// also ack other consumers' unacked deliveries
+var ErrBatchAckSharedChannel = errors.New("batch ack requires a dedicated ChannelID")
+
+// ErrHandlerTimeout is the handler error recorded when a message handler runs
+// longer than ConsumeOptions.HandlerTimeout; the message is then retried
+var ErrHandlerTimeout = errors.New("message handler timed out")
+
+// ErrPoison marks a message that can never be processed successfully
+// (malformed JSON, unknown schema version, ...). Handlers return it, directly
+// or wrapped, to skip the retry strategy and route the message straight to the
+// DLQ (or drop it when no DLQ is configured).
+var ErrPoison = errors.New("poison message")
+
+// PoisonError wraps a handler error and marks it as non-retryable.
+// errors.Is(err, ErrPoison) reports true for it, and errors.Unwrap returns the cause.
+type PoisonError struct {
+	Err error
+}
+
+// NewPoisonError wraps err as a non-retryable handler error
+func NewPoisonError(err error) *PoisonError {
+	return &PoisonError{Err: err}
+}
+
+func (e *PoisonError) Error() string {
+	if e.Err == nil {
+		return ErrPoison.Error()
+	}
+	return ErrPoison.Error() + ": " + e.Err.Error()
+}
+
+func (e *PoisonError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is(err, ErrPoison) match any PoisonError
+func (e *PoisonError) Is(target error) bool {
+	return target == ErrPoison
+}
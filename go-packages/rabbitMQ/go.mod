@@ -2,4 +2,20 @@ module weedza.shop/rabbitmq
 
 go 1.25.1
 
-require github.com/rabbitmq/amqp091-go v1.10.0
+require (
+	dizzycoder1112/logger v0.0.0
+	github.com/rabbitmq/amqp091-go v1.10.0
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/prometheus/client_golang v1.19.1
+	github.com/prometheus/client_model v0.5.0 // indirect
+	github.com/prometheus/common v0.48.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
+
+replace dizzycoder1112/logger => ../logger
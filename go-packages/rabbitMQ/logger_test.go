@@ -0,0 +1,48 @@
+package rabbitmq
+
+import (
+	"testing"
+
+	"dizzycoder1112/logger"
+)
+
+// countingLogger counts the messages it receives per level
+type countingLogger struct {
+	counts map[string]int
+}
+
+func (l *countingLogger) Info(msg string, context ...any)  { l.counts["info"]++ }
+func (l *countingLogger) Debug(msg string, context ...any) { l.counts["debug"]++ }
+func (l *countingLogger) Error(msg string, context ...any) { l.counts["error"]++ }
+func (l *countingLogger) Warn(msg string, context ...any)  { l.counts["warn"]++ }
+
+func TestNewConsoleLoggerUsesSharedConsole(t *testing.T) {
+	if _, ok := NewConsoleLogger(ConsoleLoggerOptions{Colored: true}).(*logger.ConsoleLogger); !ok {
+		t.Fatal("NewConsoleLogger does not return the go-packages/logger ConsoleLogger")
+	}
+	if _, ok := NewConsoleLogger(ConsoleLoggerOptions{HideDebug: true}).(hideDebugLogger); !ok {
+		t.Fatal("HideDebug does not wrap the console logger")
+	}
+}
+
+func TestHideDebugLoggerDropsDebug(t *testing.T) {
+	inner := &countingLogger{counts: make(map[string]int)}
+	log := hideDebugLogger{inner}
+
+	log.Debug("dropped")
+	log.Info("kept")
+	log.Warn("kept")
+	log.Error("kept")
+
+	if inner.counts["debug"] != 0 {
+		t.Fatalf("debug messages forwarded: %d", inner.counts["debug"])
+	}
+	if inner.counts["info"] != 1 || inner.counts["warn"] != 1 || inner.counts["error"] != 1 {
+		t.Fatalf("counts = %v, want one info, warn and error", inner.counts)
+	}
+}
+
+func TestSharedLoggerSatisfiesLogger(t *testing.T) {
+	// any logger.Logger can be passed to NewConnection without an adapter
+	var _ Logger = logger.Default
+}
@@ -0,0 +1,75 @@
+package rabbitmq
+
+import (
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Middleware wraps a MessageHandler, e.g. to add logging around every message
+type Middleware func(next MessageHandler) MessageHandler
+
+// Chain wraps handler with middlewares; the first middleware is the outermost:
+//
+//	handler = rabbitmq.Chain(handle, rabbitmq.LoggingMiddleware(logger, nil))
+func Chain(handler MessageHandler, middlewares ...Middleware) MessageHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// DefaultLogHeaders are the delivery headers LoggingMiddleware logs when no allowlist is given
+var DefaultLogHeaders = []string{"x-retry-count", "x-original-queue", "correlation_id"}
+
+// LoggingMiddleware logs every message after its handler returns, with the
+// handler duration, the routing key and the allowlisted delivery headers
+// (DefaultLogHeaders when headers is nil). Headers missing from the delivery
+// are omitted. "correlation_id" falls back to the AMQP CorrelationId property
+// when no header of that name is set.
+func LoggingMiddleware(logger Logger, headers []string) Middleware {
+	if logger == nil {
+		logger = defaultLogger
+	}
+	if headers == nil {
+		headers = DefaultLogHeaders
+	}
+
+	return func(next MessageHandler) MessageHandler {
+		return func(payload []byte, delivery amqp.Delivery) error {
+			start := time.Now()
+			err := next(payload, delivery)
+
+			fields := map[string]interface{}{
+				"routingKey": delivery.RoutingKey,
+				"duration":   time.Since(start).String(),
+			}
+			for key, value := range deliveryHeaders(delivery, headers) {
+				fields[key] = value
+			}
+
+			if err != nil {
+				fields["error"] = err.Error()
+				logger.Error("Message handler failed", fields)
+				return err
+			}
+			logger.Info("Message handled", fields)
+			return nil
+		}
+	}
+}
+
+// deliveryHeaders picks the allowlisted keys out of delivery.Headers
+func deliveryHeaders(delivery amqp.Delivery, keys []string) map[string]interface{} {
+	picked := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if value, ok := delivery.Headers[key]; ok {
+			picked[key] = value
+			continue
+		}
+		if key == "correlation_id" && delivery.CorrelationId != "" {
+			picked[key] = delivery.CorrelationId
+		}
+	}
+	return picked
+}
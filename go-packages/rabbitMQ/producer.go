@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -24,6 +25,12 @@ func PublishToExchange(
 		publishOptions = &defaultPublishOpts
 	}
 
+	done, err := conn.beginPublish()
+	if err != nil {
+		return err
+	}
+	defer done()
+
 	channel, err := conn.GetChannel(publishOptions.ChannelID)
 	if err != nil {
 		return err
@@ -43,16 +50,18 @@ func PublishToExchange(
 		publishOptions = &defaultPublishOpts
 	}
 
+	// Derive the routing key from the payload when RoutingKeyFunc is set
+	routingKey, err = publishOptions.routingKey(routingKey, payload)
+	if err != nil {
+		logger.Error("Invalid routing key", map[string]interface{}{
+			"error":    err.Error(),
+			"exchange": exchange,
+		})
+		return err
+	}
+
 	// Ensure exchange exists
-	err = channel.ExchangeDeclare(
-		exchange,
-		exchangeOptions.Type,
-		exchangeOptions.Durable,
-		exchangeOptions.AutoDelete,
-		exchangeOptions.Internal,
-		exchangeOptions.NoWait,
-		exchangeOptions.Args,
-	)
+	err = declareExchange(channel, exchange, exchangeOptions, publishOptions.PassiveDeclare)
 	if err != nil {
 		logger.Error("Failed to declare exchange", map[string]interface{}{
 			"error":    err.Error(),
@@ -79,18 +88,25 @@ func PublishToExchange(
 		Body:         message,
 		DeliveryMode: amqp.Transient,
 		Priority:     publishOptions.Priority,
-		Headers:      publishOptions.Headers,
+		Headers:      publishOptions.headers(),
 	}
 
 	if publishOptions.Persistent {
 		publishing.DeliveryMode = amqp.Persistent
 	}
+	publishOptions.setProperties(&publishing)
 
-	if publishOptions.Expiration != "" {
-		publishing.Expiration = publishOptions.Expiration
+	expiration, err := publishOptions.expiration()
+	if err != nil {
+		logger.Error("Invalid message expiration", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
 	}
+	publishing.Expiration = expiration
 
 	// Publish message to exchange
+	publishStart := time.Now()
 	err = channel.PublishWithContext(
 		context.Background(),
 		exchange,   // exchange
@@ -99,6 +115,7 @@ func PublishToExchange(
 		false,      // immediate
 		publishing,
 	)
+	publishDuration := time.Since(publishStart)
 
 	if err != nil {
 		logger.Error("Failed to publish message to exchange", map[string]interface{}{
@@ -119,6 +136,7 @@ func PublishToExchange(
 		"routingKey":  routingKey,
 		"payloadSize": len(message),
 		"channelId":   channelID,
+		"durationMs":  publishDuration.Milliseconds(),
 	})
 
 	return nil
@@ -137,6 +155,12 @@ func PublishToQueue(
 		options = &defaultOpts
 	}
 
+	done, err := conn.beginPublish()
+	if err != nil {
+		return err
+	}
+	defer done()
+
 	channel, err := conn.GetChannel(options.ChannelID)
 	if err != nil {
 		return err
@@ -159,14 +183,7 @@ func PublishToQueue(
 		}
 
 		// Assert queue
-		_, err = channel.QueueDeclare(
-			queue,
-			options.QueueOptions.Durable,
-			options.QueueOptions.AutoDelete,
-			options.QueueOptions.Exclusive,
-			options.QueueOptions.NoWait,
-			options.QueueOptions.Args,
-		)
+		err = declareQueue(channel, queue, options.QueueOptions, options.PassiveDeclare)
 		if err != nil {
 			logger.Error("Failed to declare queue", map[string]interface{}{
 				"error": err.Error(),
@@ -174,6 +191,14 @@ func PublishToQueue(
 			})
 			return fmt.Errorf("failed to declare queue %s: %w", queue, err)
 		}
+	} else if options.VerifyQueueExists {
+		if err := conn.verifyQueueExists(queue); err != nil {
+			logger.Error("Queue verification failed, not publishing", map[string]interface{}{
+				"error": err.Error(),
+				"queue": queue,
+			})
+			return err
+		}
 	}
 
 	// Marshal payload to JSON
@@ -192,18 +217,25 @@ func PublishToQueue(
 		Body:         message,
 		DeliveryMode: amqp.Transient,
 		Priority:     options.Priority,
-		Headers:      options.Headers,
+		Headers:      options.headers(),
 	}
 
 	if options.Persistent {
 		publishing.DeliveryMode = amqp.Persistent
 	}
+	options.setProperties(&publishing)
 
-	if options.Expiration != "" {
-		publishing.Expiration = options.Expiration
+	expiration, err := options.expiration()
+	if err != nil {
+		logger.Error("Invalid message expiration", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
 	}
+	publishing.Expiration = expiration
 
 	// Publish message
+	publishStart := time.Now()
 	err = channel.PublishWithContext(
 		context.Background(),
 		"",    // exchange
@@ -212,6 +244,7 @@ func PublishToQueue(
 		false, // immediate
 		publishing,
 	)
+	publishDuration := time.Since(publishStart)
 
 	if err != nil {
 		channelID := "default"
@@ -235,6 +268,7 @@ func PublishToQueue(
 		"queue":       queue,
 		"payloadSize": len(message),
 		"channelId":   channelID,
+		"durationMs":  publishDuration.Milliseconds(),
 	})
 
 	return nil
@@ -253,6 +287,12 @@ func PublishToQueueRaw(
 		options = &defaultOpts
 	}
 
+	done, err := conn.beginPublish()
+	if err != nil {
+		return err
+	}
+	defer done()
+
 	channel, err := conn.GetChannel(options.ChannelID)
 	if err != nil {
 		return err
@@ -275,14 +315,7 @@ func PublishToQueueRaw(
 		}
 
 		// Assert queue
-		_, err = channel.QueueDeclare(
-			queue,
-			options.QueueOptions.Durable,
-			options.QueueOptions.AutoDelete,
-			options.QueueOptions.Exclusive,
-			options.QueueOptions.NoWait,
-			options.QueueOptions.Args,
-		)
+		err = declareQueue(channel, queue, options.QueueOptions, options.PassiveDeclare)
 		if err != nil {
 			logger.Error("Failed to declare queue", map[string]interface{}{
 				"error": err.Error(),
@@ -290,6 +323,14 @@ func PublishToQueueRaw(
 			})
 			return fmt.Errorf("failed to declare queue %s: %w", queue, err)
 		}
+	} else if options.VerifyQueueExists {
+		if err := conn.verifyQueueExists(queue); err != nil {
+			logger.Error("Queue verification failed, not publishing", map[string]interface{}{
+				"error": err.Error(),
+				"queue": queue,
+			})
+			return err
+		}
 	}
 
 	// Prepare publishing options
@@ -298,18 +339,25 @@ func PublishToQueueRaw(
 		Body:         message,
 		DeliveryMode: amqp.Transient,
 		Priority:     options.Priority,
-		Headers:      options.Headers,
+		Headers:      options.headers(),
 	}
 
 	if options.Persistent {
 		publishing.DeliveryMode = amqp.Persistent
 	}
+	options.setProperties(&publishing)
 
-	if options.Expiration != "" {
-		publishing.Expiration = options.Expiration
+	expiration, err := options.expiration()
+	if err != nil {
+		logger.Error("Invalid message expiration", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
 	}
+	publishing.Expiration = expiration
 
 	// Publish message
+	publishStart := time.Now()
 	err = channel.PublishWithContext(
 		context.Background(),
 		"",    // exchange
@@ -318,6 +366,7 @@ func PublishToQueueRaw(
 		false, // immediate
 		publishing,
 	)
+	publishDuration := time.Since(publishStart)
 
 	if err != nil {
 		channelID := "default"
@@ -341,7 +390,27 @@ func PublishToQueueRaw(
 		"queue":       queue,
 		"payloadSize": len(message),
 		"channelId":   channelID,
+		"durationMs":  publishDuration.Milliseconds(),
 	})
 
 	return nil
 }
+
+// PublishToQueueWithTTL publishes a message to a queue that expires after ttl.
+// Expired messages are dead-lettered if the queue has a DLX configured
+// (e.g. consumers started with EnableDLQ), otherwise they are dropped.
+func PublishToQueueWithTTL(
+	conn *Connection,
+	queue string,
+	payload interface{},
+	ttl time.Duration,
+	options *PublishOptions,
+) error {
+	opts := DefaultPublishOptions()
+	if options != nil {
+		opts = *options
+	}
+	opts.ExpirationDuration = ttl
+
+	return PublishToQueue(conn, queue, payload, &opts)
+}
@@ -0,0 +1,72 @@
+// Package promstats provides a Prometheus implementation of the
+// rabbitmq ConsumeOptions.OnMessageProcessed hook. It lives in its own
+// package so the core library does not import Prometheus.
+package promstats
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusStats records consumer throughput, retries, failures and handler latency per queue
+type PrometheusStats struct {
+	processed *prometheus.CounterVec
+	retried   *prometheus.CounterVec
+	failed    *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+}
+
+// NewPrometheusStats creates the collectors and registers them with reg.
+// Pass prometheus.DefaultRegisterer to expose them on the default /metrics handler.
+func NewPrometheusStats(reg prometheus.Registerer, namespace string) (*PrometheusStats, error) {
+	s := &PrometheusStats{
+		processed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rabbitmq_consumer",
+			Name:      "messages_processed_total",
+			Help:      "Messages handled by the consumer, including failures.",
+		}, []string{"queue"}),
+		retried: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rabbitmq_consumer",
+			Name:      "messages_retried_total",
+			Help:      "Messages handled on a retry attempt (x-retry-count > 0).",
+		}, []string{"queue"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rabbitmq_consumer",
+			Name:      "messages_failed_total",
+			Help:      "Messages whose handler returned an error.",
+		}, []string{"queue"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "rabbitmq_consumer",
+			Name:      "handler_duration_seconds",
+			Help:      "Time spent in the message handler.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"queue"}),
+	}
+
+	for _, c := range []prometheus.Collector{s.processed, s.retried, s.failed, s.latency} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// OnMessageProcessed matches rabbitmq.ConsumeOptions.OnMessageProcessed:
+//
+//	opts.OnMessageProcessed = stats.OnMessageProcessed
+func (s *PrometheusStats) OnMessageProcessed(queue string, success bool, attempt int, dur time.Duration) {
+	s.processed.WithLabelValues(queue).Inc()
+	if attempt > 0 {
+		s.retried.WithLabelValues(queue).Inc()
+	}
+	if !success {
+		s.failed.WithLabelValues(queue).Inc()
+	}
+	s.latency.WithLabelValues(queue).Observe(dur.Seconds())
+}
@@ -0,0 +1,244 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// Attempts and initial delay (doubled each attempt) for reviving consumers after a channel close
+	channelRecoveryAttempts  = 5
+	channelRecoveryBaseDelay = time.Second
+)
+
+// activeConsumer holds what ConsumeQueue needs to restart a consumer, plus
+// the consumer's runtime state. options is a private copy of the caller's
+// options, so reusing one ConsumeOptions value for several consumers shares
+// neither the generated consumer tag nor any of this state.
+type activeConsumer struct {
+	queue   string
+	handler consumeHandler
+	options *ConsumeOptions
+
+	stopped atomic.Bool    // Set when the consumer is cancelled (see RequeueOnStop)
+	loops   sync.WaitGroup // Delivery loops, so Consumer.Stop can wait for them
+	dedup   *dedupCache    // Kept across channel recovery so handled IDs aren't forgotten
+}
+
+// newActiveConsumer copies options (nil uses the ConsumeQueue defaults) and
+// sets up the consumer's runtime state
+func newActiveConsumer(queue string, handler consumeHandler, options *ConsumeOptions) *activeConsumer {
+	opts := ConsumeOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	// setupDLQ writes the DLX arguments into QueueOptions.Args, so each consumer
+	// needs its own QueueOptions and Args map even when the caller shares them
+	if opts.QueueOptions != nil {
+		queueOpts := *opts.QueueOptions
+		if queueOpts.Args != nil {
+			args := make(amqp.Table, len(queueOpts.Args))
+			for k, v := range queueOpts.Args {
+				args[k] = v
+			}
+			queueOpts.Args = args
+		}
+		opts.QueueOptions = &queueOpts
+	}
+
+	// Use a known consumer tag so Drain can cancel the consumer (kept on recovery)
+	if opts.ConsumerTag == "" {
+		opts.ConsumerTag = fmt.Sprintf("ctag-%s-%d", queue, consumerSeq.Add(1))
+	}
+
+	consumer := &activeConsumer{
+		queue:   queue,
+		handler: handler,
+		options: &opts,
+	}
+	if opts.Deduplication != nil {
+		consumer.dedup = newDedupCache(opts.Deduplication)
+	}
+	return consumer
+}
+
+// markStopped records that the consumer was cancelled (see RequeueOnStop)
+func (c *activeConsumer) markStopped() {
+	c.stopped.Store(true)
+}
+
+// isStopped reports whether the consumer was cancelled
+func (c *activeConsumer) isStopped() bool {
+	return c.stopped.Load()
+}
+
+// trackConsumer registers a running consumer for channel recovery
+func (c *Connection) trackConsumer(consumer *activeConsumer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.consumers = append(c.consumers, consumer)
+}
+
+// untrackConsumer stops reviving consumers with the given tag and returns the
+// removed consumer (nil when it was not tracked)
+func (c *Connection) untrackConsumer(consumerTag string) *activeConsumer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var removed *activeConsumer
+	kept := c.consumers[:0]
+	for _, consumer := range c.consumers {
+		if consumer.options.ConsumerTag != consumerTag {
+			kept = append(kept, consumer)
+		} else {
+			removed = consumer
+		}
+	}
+	c.consumers = kept
+	return removed
+}
+
+// recoverChannel re-opens a channel closed with an error and restarts its consumers.
+// The connection must still be open; connection-level failures are not handled here.
+func (c *Connection) recoverChannel(channelID string) {
+	// Consumers store "" for the default channel
+	key := channelID
+	if channelID == "default" {
+		key = ""
+	}
+
+	c.mu.Lock()
+	if c.closed || c.draining || c.conn == nil || c.conn.IsClosed() || c.recovering[key] {
+		// A failed re-declare during recovery closes the new channel again;
+		// the running recovery loop handles the retry.
+		c.mu.Unlock()
+		return
+	}
+
+	var consumers []*activeConsumer
+	for _, consumer := range c.consumers {
+		if consumer.options.ChannelID == key {
+			consumers = append(consumers, consumer)
+		}
+	}
+	if len(consumers) == 0 && key != "" {
+		c.mu.Unlock()
+		return
+	}
+	c.recovering[key] = true
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.recovering, key)
+		c.mu.Unlock()
+	}()
+
+	for attempt := 0; attempt < channelRecoveryAttempts; attempt++ {
+		time.Sleep(channelRecoveryBaseDelay << attempt)
+
+		err := c.reviveConsumers(key, consumers)
+		if err == nil {
+			c.mu.Lock()
+			c.counters.channelRecoveries++
+			c.mu.Unlock()
+
+			c.logger.Info("Channel recovered", map[string]interface{}{
+				"channelId": channelID,
+				"consumers": len(consumers),
+			})
+			return
+		}
+
+		c.logger.Error("Failed to recover channel", map[string]interface{}{
+			"error":     err.Error(),
+			"channelId": channelID,
+			"attempt":   attempt + 1,
+		})
+	}
+
+	c.logger.Error("Giving up channel recovery", map[string]interface{}{
+		"channelId": channelID,
+		"attempts":  channelRecoveryAttempts,
+	})
+}
+
+// reviveConsumers re-opens the channel and restarts each consumer on it
+func (c *Connection) reviveConsumers(channelID string, consumers []*activeConsumer) error {
+	if channelID == "" {
+		if err := c.reopenDefaultChannel(); err != nil {
+			return err
+		}
+	}
+
+	for _, consumer := range consumers {
+		if err := startConsuming(c, consumer, nil); err != nil {
+			return fmt.Errorf("failed to restart consumer on queue %s: %w", consumer.queue, err)
+		}
+	}
+
+	return nil
+}
+
+// getDefaultChannel returns the default channel, re-opening it first when it was
+// closed (e.g. by a failed passive declare) while the connection is still up, so a
+// single channel error does not fail every later publish until the connection drops
+func (c *Connection) getDefaultChannel() (*amqp.Channel, error) {
+	c.mu.RLock()
+	channel := c.defaultChannel
+	connAlive := c.conn != nil && !c.conn.IsClosed()
+	c.mu.RUnlock()
+
+	if channel == nil {
+		return nil, errors.New("default channel not initialized. Call Connect() first")
+	}
+	if !channel.IsClosed() || !connAlive {
+		// A dropped connection is handled by the connection-level reconnect
+		return channel, nil
+	}
+
+	c.logger.Warn("Default channel is closed, re-opening it", nil)
+	if err := c.reopenDefaultChannel(); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.defaultChannel, nil
+}
+
+// reopenDefaultChannel replaces a closed default channel with a new one (QoS re-applied)
+func (c *Connection) reopenDefaultChannel() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil || c.closed {
+		return fmt.Errorf("connection not initialized")
+	}
+	if c.defaultChannel != nil && !c.defaultChannel.IsClosed() {
+		return nil
+	}
+
+	channel, err := c.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to re-open default channel: %w", err)
+	}
+
+	if c.config.Prefetch > 0 {
+		if err := channel.Qos(c.config.Prefetch, 0, false); err != nil {
+			channel.Close()
+			return fmt.Errorf("failed to set QoS: %w", err)
+		}
+	}
+
+	c.defaultChannel = channel
+	c.counters.channelsOpened++
+	c.setupChannelHandlers(channel, "default")
+	return nil
+}
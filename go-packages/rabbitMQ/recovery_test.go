@@ -0,0 +1,67 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestNewActiveConsumerCopiesOptions(t *testing.T) {
+	handler := func(ctx context.Context, payload []byte, delivery amqp.Delivery, ack *Acknowledger) error { return nil }
+	shared := &ConsumeOptions{ChannelID: "workers", Deduplication: &DeduplicationOptions{}}
+
+	a := newActiveConsumer("orders", handler, shared)
+	b := newActiveConsumer("orders", handler, shared)
+
+	if shared.ConsumerTag != "" {
+		t.Fatalf("caller's ConsumerTag was set to %q", shared.ConsumerTag)
+	}
+	if a.options.ConsumerTag == "" || a.options.ConsumerTag == b.options.ConsumerTag {
+		t.Fatalf("consumer tags = %q and %q, want two distinct generated tags", a.options.ConsumerTag, b.options.ConsumerTag)
+	}
+	if a.options == shared || a.options == b.options {
+		t.Fatal("consumers share the options value")
+	}
+	if a.dedup == nil || a.dedup == b.dedup {
+		t.Fatal("consumers share the dedup cache")
+	}
+
+	a.markStopped()
+	if !a.isStopped() || b.isStopped() {
+		t.Fatal("stopping one consumer stopped the other")
+	}
+}
+
+func TestNewActiveConsumerKeepsExplicitTag(t *testing.T) {
+	c := newActiveConsumer("orders", nil, &ConsumeOptions{ConsumerTag: "orders-worker"})
+	if c.options.ConsumerTag != "orders-worker" {
+		t.Fatalf("ConsumerTag = %q, want orders-worker", c.options.ConsumerTag)
+	}
+
+	if c := newActiveConsumer("orders", nil, nil); c.options.ConsumerTag == "" {
+		t.Fatal("nil options got no consumer tag")
+	}
+}
+
+func TestNewActiveConsumerCopiesQueueArgs(t *testing.T) {
+	queueOpts := DefaultQueueOptions()
+	queueOpts.Args = amqp.Table{"x-message-ttl": int32(60000)}
+	shared := &ConsumeOptions{EnableDLQ: true, QueueOptions: &queueOpts}
+
+	a := newActiveConsumer("orders", nil, shared)
+	b := newActiveConsumer("invoices", nil, shared)
+
+	// What setupDLQ does for queue orders
+	a.options.QueueOptions.Args["x-dead-letter-exchange"] = "orders.failed.dlx"
+
+	if _, ok := b.options.QueueOptions.Args["x-dead-letter-exchange"]; ok {
+		t.Fatal("DLX args of one consumer leaked into the other")
+	}
+	if _, ok := queueOpts.Args["x-dead-letter-exchange"]; ok {
+		t.Fatal("DLX args leaked into the caller's QueueOptions")
+	}
+	if b.options.QueueOptions.Args["x-message-ttl"] != int32(60000) {
+		t.Fatal("caller's queue args were not copied")
+	}
+}
@@ -0,0 +1,35 @@
+package rabbitmq
+
+// ConnectionStats is a snapshot of connection-level counters, for dashboards
+// and health checks. Counters are cumulative since NewConnection.
+type ConnectionStats struct {
+	Connected         bool   // Same as IsConnected
+	NamedChannels     int    // Named channels currently open (the default channel is not counted)
+	ChannelsOpened    uint64 // Channels opened, including the default channel and re-opened ones
+	ChannelCloses     uint64 // Channel close events observed (errors and normal closes)
+	ConnectionCloses  uint64 // Connection close events observed
+	ChannelRecoveries uint64 // Channels successfully recovered after a channel-level error
+}
+
+// connectionCounters holds the cumulative counters, guarded by Connection.mu
+type connectionCounters struct {
+	channelsOpened    uint64
+	channelCloses     uint64
+	connectionCloses  uint64
+	channelRecoveries uint64
+}
+
+// Stats returns a snapshot of the connection counters
+func (c *Connection) Stats() ConnectionStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return ConnectionStats{
+		Connected:         c.conn != nil && c.defaultChannel != nil && !c.closed,
+		NamedChannels:     len(c.channels),
+		ChannelsOpened:    c.counters.channelsOpened,
+		ChannelCloses:     c.counters.channelCloses,
+		ConnectionCloses:  c.counters.connectionCloses,
+		ChannelRecoveries: c.counters.channelRecoveries,
+	}
+}
@@ -0,0 +1,280 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// BindExchange binds destination exchange to source exchange, so messages
+// published to source with a matching routing key are also routed to destination.
+// Both exchanges must already exist. Empty channelID uses the default channel.
+func BindExchange(
+	conn *Connection,
+	destination string,
+	source string,
+	routingKey string,
+	args amqp.Table,
+	channelID string,
+) error {
+	channel, err := conn.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+
+	logger := conn.GetLogger()
+
+	if err := channel.ExchangeBind(destination, routingKey, source, false, args); err != nil {
+		logger.Error("Failed to bind exchange", map[string]interface{}{
+			"error":       err.Error(),
+			"destination": destination,
+			"source":      source,
+			"routingKey":  routingKey,
+		})
+		return fmt.Errorf("failed to bind exchange %s to %s: %w", destination, source, err)
+	}
+
+	logger.Debug("Exchange bound", map[string]interface{}{
+		"destination": destination,
+		"source":      source,
+		"routingKey":  routingKey,
+	})
+
+	return nil
+}
+
+// UnbindExchange removes a binding created by BindExchange.
+// routingKey and args must match the original binding.
+func UnbindExchange(
+	conn *Connection,
+	destination string,
+	source string,
+	routingKey string,
+	args amqp.Table,
+	channelID string,
+) error {
+	channel, err := conn.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+
+	logger := conn.GetLogger()
+
+	if err := channel.ExchangeUnbind(destination, routingKey, source, false, args); err != nil {
+		logger.Error("Failed to unbind exchange", map[string]interface{}{
+			"error":       err.Error(),
+			"destination": destination,
+			"source":      source,
+			"routingKey":  routingKey,
+		})
+		return fmt.Errorf("failed to unbind exchange %s from %s: %w", destination, source, err)
+	}
+
+	logger.Debug("Exchange unbound", map[string]interface{}{
+		"destination": destination,
+		"source":      source,
+		"routingKey":  routingKey,
+	})
+
+	return nil
+}
+
+// Header match modes for headers exchanges
+const (
+	HeadersMatchAll = "all" // every header must match
+	HeadersMatchAny = "any" // at least one header must match
+)
+
+// BindQueueHeaders declares a durable headers exchange (if needed) and binds
+// queue to it. Messages are routed when their headers match headers according
+// to match (HeadersMatchAll or HeadersMatchAny). Empty channelID uses the default channel.
+func BindQueueHeaders(
+	conn *Connection,
+	queue string,
+	exchange string,
+	match string,
+	headers amqp.Table,
+	channelID string,
+) error {
+	if match != HeadersMatchAll && match != HeadersMatchAny {
+		return fmt.Errorf("invalid x-match %q: must be %q or %q", match, HeadersMatchAll, HeadersMatchAny)
+	}
+	if len(headers) == 0 {
+		return fmt.Errorf("headers binding for queue %s needs at least one header", queue)
+	}
+
+	channel, err := conn.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+
+	logger := conn.GetLogger()
+
+	exchangeOptions := headersExchangeOptions()
+	err = channel.ExchangeDeclare(
+		exchange,
+		exchangeOptions.Type,
+		exchangeOptions.Durable,
+		exchangeOptions.AutoDelete,
+		exchangeOptions.Internal,
+		exchangeOptions.NoWait,
+		exchangeOptions.Args,
+	)
+	if err != nil {
+		logger.Error("Failed to declare exchange", map[string]interface{}{
+			"error":    err.Error(),
+			"exchange": exchange,
+			"type":     exchangeOptions.Type,
+		})
+		return fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+	}
+
+	args := amqp.Table{"x-match": match}
+	for k, v := range headers {
+		args[k] = v
+	}
+
+	if err := channel.QueueBind(queue, "", exchange, false, args); err != nil {
+		logger.Error("Failed to bind queue to headers exchange", map[string]interface{}{
+			"error":    err.Error(),
+			"queue":    queue,
+			"exchange": exchange,
+		})
+		return fmt.Errorf("failed to bind queue %s to headers exchange %s: %w", queue, exchange, err)
+	}
+
+	logger.Debug("Queue bound to headers exchange", map[string]interface{}{
+		"queue":    queue,
+		"exchange": exchange,
+		"match":    match,
+	})
+
+	return nil
+}
+
+// PublishWithHeaders publishes payload to a headers exchange with the given
+// headers (merged over publishOptions.Headers). The routing key is ignored by
+// headers exchanges, so none is set.
+func PublishWithHeaders(
+	conn *Connection,
+	exchange string,
+	headers amqp.Table,
+	payload interface{},
+	publishOptions *PublishOptions,
+) error {
+	opts := DefaultPublishOptions()
+	if publishOptions != nil {
+		opts = *publishOptions
+	}
+
+	merged := amqp.Table{}
+	for k, v := range opts.Headers {
+		merged[k] = v
+	}
+	for k, v := range headers {
+		merged[k] = v
+	}
+	opts.Headers = merged
+
+	exchangeOptions := headersExchangeOptions()
+	return PublishToExchange(conn, exchange, "", payload, &exchangeOptions, &opts)
+}
+
+// headersExchangeOptions returns DefaultExchangeOptions with type "headers"
+func headersExchangeOptions() ExchangeOptions {
+	opts := DefaultExchangeOptions()
+	opts.Type = "headers"
+	return opts
+}
+
+// declareQueue declares queue with opts, or only checks that it exists when passive is set.
+// A missing queue in passive mode is reported as ErrTopologyNotFound; note that the
+// broker closes the channel on that failure.
+func declareQueue(channel *amqp.Channel, queue string, opts *QueueOptions, passive bool) error {
+	declare := channel.QueueDeclare
+	if passive {
+		declare = channel.QueueDeclarePassive
+	}
+
+	args, err := opts.declareArgs()
+	if err != nil {
+		return err
+	}
+
+	_, err = declare(
+		queue,
+		opts.Durable,
+		opts.AutoDelete,
+		opts.Exclusive,
+		opts.NoWait,
+		args,
+	)
+	if err != nil {
+		if passive && isNotFound(err) {
+			return fmt.Errorf("queue %s: %w", queue, ErrTopologyNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+// declareExchange declares exchange with opts, or only checks that it exists when passive is set
+func declareExchange(channel *amqp.Channel, exchange string, opts *ExchangeOptions, passive bool) error {
+	declare := channel.ExchangeDeclare
+	if passive {
+		declare = channel.ExchangeDeclarePassive
+	}
+
+	err := declare(
+		exchange,
+		opts.Type,
+		opts.Durable,
+		opts.AutoDelete,
+		opts.Internal,
+		opts.NoWait,
+		opts.Args,
+	)
+	if err != nil {
+		if passive && isNotFound(err) {
+			return fmt.Errorf("exchange %s: %w", exchange, ErrTopologyNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+// verifyQueueExists checks that queue exists without creating it. The passive
+// declare runs on a short-lived channel, because the broker closes the channel
+// when the queue is missing and the publish channel must stay usable.
+// A missing queue is reported as ErrTopologyNotFound.
+func (c *Connection) verifyQueueExists(queue string) error {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	if conn == nil {
+		return errors.New("connection not initialized. Call Connect() first")
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel to verify queue %s: %w", queue, err)
+	}
+	// Already closed by the broker when the queue is missing
+	defer channel.Close()
+
+	if _, err := channel.QueueDeclarePassive(queue, false, false, false, false, nil); err != nil {
+		if isNotFound(err) {
+			return fmt.Errorf("queue %s does not exist: %w", queue, ErrTopologyNotFound)
+		}
+		return fmt.Errorf("failed to verify queue %s: %w", queue, err)
+	}
+	return nil
+}
+
+// isNotFound reports whether err is the broker's 404 NOT_FOUND channel exception
+func isNotFound(err error) bool {
+	var amqpErr *amqp.Error
+	return errors.As(err, &amqpErr) && amqpErr.Code == amqp.NotFound
+}
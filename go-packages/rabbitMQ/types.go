@@ -1,11 +1,21 @@
 package rabbitmq
 
-import amqp "github.com/rabbitmq/amqp091-go"
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
 
 // Logger interface for custom logging implementations
 // Supports variadic context arguments in two formats:
 // 1. Key-value pairs: "key1", value1, "key2", value2
 // 2. Single map: map[string]any{"key1": value1, "key2": value2}
+//
+// It is a subset of the shared logger.Logger in go-packages/logger, so the
+// console, Zap or multi loggers from that package can be passed to
+// NewConnection directly without an adapter.
 type Logger interface {
 	Info(msg string, context ...any)
 	Debug(msg string, context ...any)
@@ -26,6 +36,58 @@ type QueueOptions struct {
 	Exclusive  bool
 	NoWait     bool
 	Args       amqp.Table
+
+	// QueueType sets x-queue-type (QueueTypeClassic, QueueTypeQuorum or
+	// QueueTypeStream); empty leaves the broker default. Quorum and stream queues
+	// must be durable and cannot be exclusive or auto-delete.
+	QueueType string
+
+	// MaxPriority sets x-max-priority, making a classic priority queue that
+	// delivers messages with a higher PublishOptions.Priority first. Without it
+	// the broker ignores Priority. Publishers should keep Priority <= MaxPriority
+	// (higher values are treated as MaxPriority); 0 leaves the queue unprioritized.
+	// RabbitMQ recommends at most 10 levels. Not supported by quorum or stream queues.
+	MaxPriority uint8
+}
+
+// Queue types accepted by QueueOptions.QueueType
+const (
+	QueueTypeClassic = "classic"
+	QueueTypeQuorum  = "quorum"
+	QueueTypeStream  = "stream"
+)
+
+// declareArgs returns Args with x-queue-type added for QueueType and
+// x-max-priority for MaxPriority. Args itself is not modified.
+func (o *QueueOptions) declareArgs() (amqp.Table, error) {
+	switch o.QueueType {
+	case "", QueueTypeClassic:
+	case QueueTypeQuorum, QueueTypeStream:
+		if !o.Durable || o.Exclusive || o.AutoDelete {
+			return nil, fmt.Errorf("%w: %s queues must be durable, non-exclusive and not auto-delete", ErrInvalidQueueType, o.QueueType)
+		}
+		if o.MaxPriority > 0 {
+			return nil, fmt.Errorf("%w: %s queues do not support MaxPriority", ErrInvalidQueueType, o.QueueType)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q (want %s, %s or %s)", ErrInvalidQueueType, o.QueueType, QueueTypeClassic, QueueTypeQuorum, QueueTypeStream)
+	}
+
+	if o.QueueType == "" && o.MaxPriority == 0 {
+		return o.Args, nil
+	}
+
+	args := amqp.Table{}
+	for k, v := range o.Args {
+		args[k] = v
+	}
+	if o.QueueType != "" {
+		args["x-queue-type"] = o.QueueType
+	}
+	if o.MaxPriority > 0 {
+		args["x-max-priority"] = int32(o.MaxPriority)
+	}
+	return args, nil
 }
 
 // DefaultQueueOptions returns default queue options
@@ -66,11 +128,42 @@ func DefaultExchangeOptions() ExchangeOptions {
 type PublishOptions struct {
 	Persistent         bool
 	Priority           uint8
-	Expiration         string
+	Expiration         string        // Raw AMQP expiration in milliseconds, e.g. "60000"
+	ExpirationDuration time.Duration // Per-message TTL; takes precedence over Expiration when non-zero
 	Headers            amqp.Table
 	QueueOptions       *QueueOptions
 	EnableQueueDeclare bool   // Enable queue declaration (default: false, assume queue already exists)
 	ChannelID          string // Optional channel ID for channel isolation. Empty string uses default channel.
+
+	// PassiveDeclare only checks that the exchange (PublishToExchange) or queue
+	// (when EnableQueueDeclare is set) exists, without creating or changing it.
+	PassiveDeclare bool
+
+	// RoutingKeyFunc derives the routing key from the payload in PublishToExchange
+	// (e.g. "events.pr.opened"), replacing the routingKey argument. The result must
+	// be non-empty and at most 255 bytes, otherwise ErrInvalidRoutingKey is returned.
+	RoutingKeyFunc func(payload interface{}) string
+
+	// VerifyQueueExists makes PublishToQueue and PublishToQueueRaw check, with a
+	// passive declare, that the queue exists before publishing, and fail with
+	// ErrTopologyNotFound instead of letting the broker drop the message. The
+	// check never creates the queue and costs a channel open and a round trip per
+	// publish. It is skipped when EnableQueueDeclare is set.
+	VerifyQueueExists bool
+
+	// DeduplicationID is sent as the x-deduplication-id header. Consumers with
+	// ConsumeOptions.Deduplication skip messages whose ID they already handled,
+	// which makes producer retries idempotent (best effort, see DeduplicationOptions).
+	DeduplicationID string
+
+	// Standard AMQP message properties, set on every publish function; empty
+	// strings are omitted. CorrelationID ties a reply to its request (RPC) and
+	// MessageID identifies the message for tracing; Type and AppID describe
+	// the payload kind and the publishing service.
+	CorrelationID string
+	MessageID     string
+	Type          string
+	AppID         string
 }
 
 // DefaultPublishOptions returns default publish options
@@ -86,6 +179,104 @@ func DefaultPublishOptions() PublishOptions {
 	}
 }
 
+// expiration returns the AMQP expiration string for the message.
+// Expired messages are dead-lettered when the queue has a DLX, otherwise dropped.
+func (o *PublishOptions) expiration() (string, error) {
+	if o.ExpirationDuration < 0 {
+		return "", fmt.Errorf("invalid expiration %s: must not be negative", o.ExpirationDuration)
+	}
+	if o.ExpirationDuration > 0 {
+		ms := o.ExpirationDuration.Milliseconds()
+		if ms == 0 {
+			ms = 1 // "0" would expire immediately unless a consumer is waiting
+		}
+		return strconv.FormatInt(ms, 10), nil
+	}
+	return o.Expiration, nil
+}
+
+// headers returns the message headers, adding DeduplicationID without
+// modifying the caller's Headers table
+func (o *PublishOptions) headers() amqp.Table {
+	if o.DeduplicationID == "" {
+		return o.Headers
+	}
+
+	headers := make(amqp.Table, len(o.Headers)+1)
+	for k, v := range o.Headers {
+		headers[k] = v
+	}
+	headers[DeduplicationHeader] = o.DeduplicationID
+	return headers
+}
+
+// setProperties copies the AMQP message properties onto publishing
+func (o *PublishOptions) setProperties(publishing *amqp.Publishing) {
+	publishing.CorrelationId = o.CorrelationID
+	publishing.MessageId = o.MessageID
+	publishing.Type = o.Type
+	publishing.AppId = o.AppID
+}
+
+// maxRoutingKeyLength is the AMQP short string limit for routing keys
+const maxRoutingKeyLength = 255
+
+// routingKey returns the key to publish with: RoutingKeyFunc's result when set,
+// otherwise the explicit key passed by the caller
+func (o *PublishOptions) routingKey(explicit string, payload interface{}) (string, error) {
+	if o.RoutingKeyFunc == nil {
+		if len(explicit) > maxRoutingKeyLength {
+			return "", fmt.Errorf("%w: %d bytes exceeds %d", ErrInvalidRoutingKey, len(explicit), maxRoutingKeyLength)
+		}
+		return explicit, nil
+	}
+
+	key := o.RoutingKeyFunc(payload)
+	if key == "" {
+		return "", fmt.Errorf("%w: RoutingKeyFunc returned an empty key", ErrInvalidRoutingKey)
+	}
+	if len(key) > maxRoutingKeyLength {
+		return "", fmt.Errorf("%w: %d bytes exceeds %d", ErrInvalidRoutingKey, len(key), maxRoutingKeyLength)
+	}
+	return key, nil
+}
+
+// DefaultStreamPrefetch is the prefetch applied to a stream consumer's channel
+// when the connection has none; RabbitMQ refuses stream consumers without QoS
+const DefaultStreamPrefetch = 100
+
+// consumeArgs returns Args with x-stream-offset added for StreamOffset.
+// Args itself is not modified.
+func (o *ConsumeOptions) consumeArgs() (amqp.Table, error) {
+	if o.StreamOffset == "" {
+		return o.Args, nil
+	}
+	if o.NoAck {
+		return nil, fmt.Errorf("%w: stream consumers must ack (NoAck is set)", ErrInvalidStreamOffset)
+	}
+
+	var offset interface{}
+	switch o.StreamOffset {
+	case "first", "last", "next":
+		offset = o.StreamOffset
+	default:
+		if n, err := strconv.ParseInt(o.StreamOffset, 10, 64); err == nil && n >= 0 {
+			offset = n
+		} else if ts, err := time.Parse(time.RFC3339, o.StreamOffset); err == nil {
+			offset = ts
+		} else {
+			return nil, fmt.Errorf("%w: %q (want first, last, next, an offset or an RFC 3339 timestamp)", ErrInvalidStreamOffset, o.StreamOffset)
+		}
+	}
+
+	args := amqp.Table{}
+	for k, v := range o.Args {
+		args[k] = v
+	}
+	args["x-stream-offset"] = offset
+	return args, nil
+}
+
 // ConsumeOptions represents consumer configuration options
 type ConsumeOptions struct {
 	NoAck         bool
@@ -97,6 +288,64 @@ type ConsumeOptions struct {
 	RetryStrategy RetryStrategy
 	EnableDLQ     bool   // Enable Dead Letter Queue for failed messages
 	ChannelID     string // Optional channel ID for channel isolation. Empty string uses default channel.
+
+	// Bindings: each exchange is declared before the queue using ExchangeOptions
+	// (DefaultExchangeOptions when nil), then the queue is bound in order.
+	// The predeclared "amq.*" exchanges are bound without being declared.
+	Bindings        []QueueBinding
+	ExchangeOptions *ExchangeOptions
+
+	// OnMessageProcessed is an optional stats hook called after each delivery is handled.
+	// attempt is the retry count from the message headers (0 on first delivery) and
+	// dur is the time spent in the handler. See the promstats sub-package for a
+	// Prometheus implementation.
+	OnMessageProcessed func(queue string, success bool, attempt int, dur time.Duration)
+
+	// PassiveDeclare is for topology pre-declared by the infrastructure: the queue
+	// and binding exchanges are only checked for existence (ErrTopologyNotFound when
+	// missing), and EnableDLQ, Bindings and RetryStrategy.Setup declare nothing.
+	// Retry and DLQ exchanges/queues must then already exist.
+	PassiveDeclare bool
+
+	// HandlerTimeout bounds each handler call (0 waits indefinitely). On timeout
+	// the message is treated as failed and goes through the RetryStrategy; the
+	// handler is abandoned but keeps running unless it honours its context (see
+	// ConsumeQueueWithContextHandler), so the retried message may overlap it.
+	HandlerTimeout time.Duration
+
+	// Deduplication acks messages whose x-deduplication-id was already handled
+	// by this consumer without calling the handler. See DeduplicationOptions.
+	Deduplication *DeduplicationOptions
+
+	// StreamOffset consumes a stream queue (QueueTypeStream) from the given
+	// position: "first", "last", "next", an integer offset or an RFC 3339
+	// timestamp. It is sent as the x-stream-offset consumer argument. Stream
+	// consumers must ack (NoAck is rejected) and need a prefetch, so
+	// DefaultStreamPrefetch is applied when Config.Prefetch is 0.
+	StreamOffset string
+
+	// RequeueOnStop: once the consumer is stopped (Drain, CancelConsumer, or
+	// ConsumeN reaching its limit), deliveries already buffered but not yet
+	// handed to the handler are nacked with requeue instead of being processed.
+	// The handler call in progress still finishes and acks normally.
+	RequeueOnStop bool
+
+	// BatchAck acks successful messages in bulk (multiple=true) instead of one
+	// by one. Requires a dedicated ChannelID (ErrBatchAckSharedChannel
+	// otherwise); see BatchAckOptions.
+	BatchAck *BatchAckOptions
+
+	// ConsumeN only: stop after MaxMessages messages were processed successfully,
+	// or after IdleTimeout passes with no delivery (0 waits indefinitely)
+	MaxMessages int
+	IdleTimeout time.Duration
+}
+
+// QueueBinding binds the consumed queue to an exchange
+type QueueBinding struct {
+	Exchange   string
+	RoutingKey string // Binding key, e.g. "order.*" for topic exchanges
+	Args       amqp.Table
 }
 
 // MessageHandler is a function type for handling consumed messages